@@ -18,6 +18,9 @@ import (
 	"time"
 )
 
+// ErrAPINotSupported is returned when the api is missing from the requested Logstash version
+var ErrAPINotSupported = errors.New("api not supported by this Logstash version")
+
 type ReqClient struct {
 	BaseUrl string
 	hc      *http.Client
@@ -38,22 +41,59 @@ type NodeRootInfo struct {
 	HttpAddress string `json:"http_address"`
 }
 
+// FlowMetrics maps a flow metric name to its values keyed by window, such as current or lifetime
+type FlowMetrics map[string]map[string]float64
+
+// UnmarshalJSON keeps the numeric flow values only, a null or non numeric value from another Logstash version
+// is skipped instead of failing the whole stats response
+func (f *FlowMetrics) UnmarshalJSON(data []byte) error {
+	var flows map[string]json.RawMessage
+	if err := json.Unmarshal(data, &flows); err != nil {
+		*f = nil
+		return nil
+	}
+	result := make(FlowMetrics, len(flows))
+	for name, raw := range flows {
+		var windows map[string]interface{}
+		if err := json.Unmarshal(raw, &windows); err != nil {
+			continue
+		}
+		for window, value := range windows {
+			number, ok := value.(float64)
+			if !ok {
+				continue
+			}
+			if result[name] == nil {
+				result[name] = make(map[string]float64)
+			}
+			result[name][window] = number
+		}
+	}
+	*f = result
+	return nil
+}
+
 // Pipeline type
 type Pipeline struct {
 	Events struct {
-		DurationInMillis int `json:"duration_in_millis"`
-		In               int `json:"in"`
-		Filtered         int `json:"filtered"`
-		Out              int `json:"out"`
+		DurationInMillis          int `json:"duration_in_millis"`
+		In                        int `json:"in"`
+		Filtered                  int `json:"filtered"`
+		Out                       int `json:"out"`
+		QueuePushDurationInMillis int `json:"queue_push_duration_in_millis"`
 	} `json:"events"`
 	Plugins struct {
 		Inputs []struct {
 			ID     string `json:"id"`
 			Events struct {
-				In  int `json:"in"`
-				Out int `json:"out"`
+				In                        int `json:"in"`
+				Out                       int `json:"out"`
+				QueuePushDurationInMillis int `json:"queue_push_duration_in_millis"`
 			} `json:"events"`
-			Name string `json:"name"`
+			Name               string      `json:"name"`
+			CurrentConnections *int64      `json:"current_connections,omitempty"` // beats, tcp and http inputs
+			PeakConnections    *int64      `json:"peak_connections,omitempty"`    // beats, tcp and http inputs
+			Flow               FlowMetrics `json:"flow"`
 		} `json:"inputs,omitempty"`
 		Filters []struct {
 			ID     string `json:"id"`
@@ -62,34 +102,53 @@ type Pipeline struct {
 				In               int `json:"in"`
 				Out              int `json:"out"`
 			} `json:"events,omitempty"`
-			Name             string `json:"name"`
-			Matches          int    `json:"matches,omitempty"`
-			Failures         int    `json:"failures,omitempty"`
-			PatternsPerField struct {
-				CapturedRequestHeaders int `json:"captured_request_headers"`
-			} `json:"patterns_per_field,omitempty"`
-			Formats int `json:"formats,omitempty"`
+			Name             string         `json:"name"`
+			Matches          *int64         `json:"matches,omitempty"`
+			Failures         *int64         `json:"failures,omitempty"`
+			PatternsPerField map[string]int `json:"patterns_per_field,omitempty"`
+			Formats          int            `json:"formats,omitempty"`
+			Flow             FlowMetrics    `json:"flow"`
 		} `json:"filters"`
 		Outputs []struct {
 			ID     string `json:"id"`
 			Events struct {
-				In  int `json:"in"`
-				Out int `json:"out"`
+				DurationInMillis int `json:"duration_in_millis"`
+				In               int `json:"in"`
+				Out              int `json:"out"`
 			} `json:"events"`
-			Name string `json:"name"`
+			Name         string `json:"name"`
+			BulkRequests *struct {
+				Successes  int64            `json:"successes"`
+				Failures   int64            `json:"failures"`
+				WithErrors int64            `json:"with_errors"`
+				Responses  map[string]int64 `json:"responses"`
+			} `json:"bulk_requests,omitempty"` // elasticsearch output
+			Documents *struct {
+				Successes            int64 `json:"successes"`
+				NonRetryableFailures int64 `json:"non_retryable_failures"`
+				RetryableFailures    int64 `json:"retryable_failures"`
+			} `json:"documents,omitempty"` // elasticsearch output
+			Flow FlowMetrics `json:"flow"`
 		} `json:"outputs"`
 	} `json:"plugins"`
 	Reloads struct {
-		LastError            interface{} `json:"last_error"`
-		Successes            int         `json:"successes"`
-		LastSuccessTimestamp interface{} `json:"last_success_timestamp"`
-		LastFailureTimestamp interface{} `json:"last_failure_timestamp"`
-		Failures             int         `json:"failures"`
+		LastError *struct {
+			Message   string   `json:"message"`
+			Backtrace []string `json:"backtrace"`
+		} `json:"last_error"`
+		Successes            int    `json:"successes"`
+		LastSuccessTimestamp string `json:"last_success_timestamp"`
+		LastFailureTimestamp string `json:"last_failure_timestamp"`
+		Failures             int    `json:"failures"`
 	} `json:"reloads"`
 	Queue struct {
-		Events   int    `json:"events"`
-		Type     string `json:"type"`
-		Capacity struct {
+		Events              int    `json:"events"`
+		EventsCount         *int64 `json:"events_count"`        // Logstash >=7
+		QueueSizeInBytes    *int64 `json:"queue_size_in_bytes"` // Logstash >=7
+		MaxQueueSizeInBytes int64  `json:"max_queue_size_in_bytes"`
+		Type                string `json:"type"`
+		Capacity            struct {
+			QueueSizeInBytes    int64 `json:"queue_size_in_bytes"` // Logstash 6
 			PageCapacityInBytes int   `json:"page_capacity_in_bytes"`
 			MaxQueueSizeInBytes int64 `json:"max_queue_size_in_bytes"`
 			MaxUnreadEvents     int   `json:"max_unread_events"`
@@ -100,9 +159,15 @@ type Pipeline struct {
 			StorageType      string `json:"storage_type"`
 		} `json:"data"`
 	} `json:"queue"`
-	DeadLetterQueue struct {
-		QueueSizeInBytes int `json:"queue_size_in_bytes"`
+	DeadLetterQueue *struct {
+		QueueSizeInBytes    int64  `json:"queue_size_in_bytes"`
+		MaxQueueSizeInBytes *int64 `json:"max_queue_size_in_bytes"` // Logstash >=7.17
+		DroppedEvents       *int64 `json:"dropped_events"`          // Logstash >=7.17
+		ExpiredEvents       *int64 `json:"expired_events"`          // Logstash >=8.4
+		LastError           string `json:"last_error"`
+		StoragePolicy       string `json:"storage_policy"`
 	} `json:"dead_letter_queue"`
+	Flow FlowMetrics `json:"flow"` // Logstash >=8.5
 }
 
 // NodeStatsInfo type
@@ -169,12 +234,176 @@ type NodeStatsInfo struct {
 		CPU struct {
 			TotalInMillis int64 `json:"total_in_millis"`
 			Percent       int   `json:"percent"`
+			LoadAverage   *struct {
+				OneMinute      float64 `json:"1m"`
+				FiveMinutes    float64 `json:"5m"`
+				FifteenMinutes float64 `json:"15m"`
+			} `json:"load_average"` // unix only
 		} `json:"cpu"`
 	} `json:"process"`
+	OS struct {
+		Cgroup *struct {
+			Cpuacct struct {
+				ControlGroup string `json:"control_group"`
+				UsageNanos   int64  `json:"usage_nanos"`
+			} `json:"cpuacct"`
+			CPU struct {
+				ControlGroup    string `json:"control_group"`
+				CfsPeriodMicros int64  `json:"cfs_period_micros"`
+				CfsQuotaMicros  int64  `json:"cfs_quota_micros"`
+				Stat            struct {
+					NumberOfElapsedPeriods int64 `json:"number_of_elapsed_periods"`
+					NumberOfTimesThrottled int64 `json:"number_of_times_throttled"`
+					TimeThrottledNanos     int64 `json:"time_throttled_nanos"`
+				} `json:"stat"`
+			} `json:"cpu"`
+		} `json:"cgroup"` // linux containers only
+	} `json:"os"`
+	GeoipDownloadManager *struct {
+		Database map[string]struct {
+			Status          string `json:"status"` // init, up_to_date, to_be_expired or expired
+			FailCheckInDays int    `json:"fail_check_in_days"`
+			LastUpdatedAt   string `json:"last_updated_at"`
+		} `json:"database"`
+		DownloadStats struct {
+			Successes     int64  `json:"successes"`
+			Failures      int64  `json:"failures"`
+			LastCheckedAt string `json:"last_checked_at"`
+			Status        string `json:"status"` // succeeded, failed or updating
+		} `json:"download_stats"`
+	} `json:"geoip_download_manager"` // Logstash >=7.14 with a geoip filter
+	Events struct {
+		DurationInMillis          int64 `json:"duration_in_millis"`
+		In                        int64 `json:"in"`
+		Filtered                  int64 `json:"filtered"`
+		Out                       int64 `json:"out"`
+		QueuePushDurationInMillis int64 `json:"queue_push_duration_in_millis"`
+	} `json:"events"`
+	Reloads struct {
+		Successes int `json:"successes"`
+		Failures  int `json:"failures"`
+	} `json:"reloads"`
+	Flow      FlowMetrics         `json:"flow"`      // Logstash >=8.5
 	Pipeline  Pipeline            `json:"pipeline"`  // Logstash 5
 	Pipelines map[string]Pipeline `json:"pipelines"` // Logstash >=6
 }
 
+// pipelinesByID returns the pipelines by id, the single pipeline of Logstash 5 is named main
+func (s *NodeStatsInfo) pipelinesByID() map[string]Pipeline {
+	if len(s.Pipelines) == 0 {
+		return map[string]Pipeline{"main": s.Pipeline}
+	}
+	return s.Pipelines
+}
+
+// NodePipelinesInfo type
+type NodePipelinesInfo struct {
+	Host      string `json:"host"`
+	Version   string `json:"version"`
+	Pipelines map[string]struct {
+		EphemeralID            string `json:"ephemeral_id"`
+		Hash                   string `json:"hash"`
+		Workers                int    `json:"workers"`
+		BatchSize              int    `json:"batch_size"`
+		BatchDelay             int    `json:"batch_delay"`
+		ConfigReloadAutomatic  bool   `json:"config_reload_automatic"`
+		DeadLetterQueueEnabled bool   `json:"dead_letter_queue_enabled"`
+	} `json:"pipelines"`
+}
+
+// NodePluginsInfo type
+type NodePluginsInfo struct {
+	Host    string `json:"host"`
+	Version string `json:"version"`
+	Total   int    `json:"total"`
+	Plugins []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"plugins"`
+}
+
+// NodeInfo type, the os and jvm sections of /_node
+type NodeInfo struct {
+	Host    string `json:"host"`
+	Version string `json:"version"`
+	OS      struct {
+		Name                string `json:"name"`
+		Arch                string `json:"arch"`
+		Version             string `json:"version"`
+		AvailableProcessors int    `json:"available_processors"`
+	} `json:"os"`
+	JVM struct {
+		Pid               int    `json:"pid"`
+		Version           string `json:"version"`
+		VMName            string `json:"vm_name"`
+		VMVersion         string `json:"vm_version"`
+		VMVendor          string `json:"vm_vendor"`
+		StartTimeInMillis int64  `json:"start_time_in_millis"`
+		Mem               struct {
+			HeapInitInBytes    int64 `json:"heap_init_in_bytes"`
+			HeapMaxInBytes     int64 `json:"heap_max_in_bytes"`
+			NonHeapInitInBytes int64 `json:"non_heap_init_in_bytes"`
+			NonHeapMaxInBytes  int64 `json:"non_heap_max_in_bytes"`
+		} `json:"mem"`
+		GCCollectors []string `json:"gc_collectors"`
+	} `json:"jvm"`
+}
+
+// NodeLoggingInfo type
+type NodeLoggingInfo struct {
+	Host    string            `json:"host"`
+	Version string            `json:"version"`
+	Loggers map[string]string `json:"loggers"`
+}
+
+// HealthIndicator is an indicator of the health report, the pipelines indicator nests one indicator per pipeline
+type HealthIndicator struct {
+	Status     string                     `json:"status"`
+	Symptom    string                     `json:"symptom"`
+	Indicators map[string]HealthIndicator `json:"indicators"`
+	Diagnosis  []struct {
+		ID      string `json:"id"`
+		Cause   string `json:"cause"`
+		Action  string `json:"action"`
+		HelpURL string `json:"help_url"`
+	} `json:"diagnosis"`
+	Impacts []struct {
+		ID          string   `json:"id"`
+		Severity    int      `json:"severity"`
+		Description string   `json:"description"`
+		ImpactAreas []string `json:"impact_areas"`
+	} `json:"impacts"`
+}
+
+// HealthReportInfo type, Logstash >=8.16
+type HealthReportInfo struct {
+	Host       string                     `json:"host"`
+	Version    string                     `json:"version"`
+	Status     string                     `json:"status"`
+	Symptom    string                     `json:"symptom"`
+	Indicators map[string]HealthIndicator `json:"indicators"`
+}
+
+// HotThreadsParams are the query params of /_node/hot_threads
+type HotThreadsParams struct {
+	Human   bool `url:"human"`
+	Threads int  `url:"threads"`
+}
+
+// HotThreadsInfo type
+type HotThreadsInfo struct {
+	HotThreads struct {
+		Time           string `json:"time"`
+		BusiestThreads int    `json:"busiest_threads"`
+		Threads        []struct {
+			Name             string  `json:"name"`
+			ThreadID         int64   `json:"thread_id"`
+			PercentOfCPUTime float64 `json:"percent_of_cpu_time"`
+			State            string  `json:"state"`
+		} `json:"threads"`
+	} `json:"hot_threads"`
+}
+
 // NewReqClient get a request client use default RoundTripper
 func NewReqClient(baseUrl string) *ReqClient {
 	return &ReqClient{
@@ -273,3 +502,174 @@ func GetLogstashNodeStats(rc *ReqClient, path string, milliseconds int64) (*Node
 	}
 	return nsi, nil
 }
+
+// GetLogstashRaw get the raw json body of a Logstash monitoring api, for gjson based collectors
+func GetLogstashRaw(rc *ReqClient, path string, milliseconds int64) ([]byte, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("GET %s unexpected status <%s>", path, resp.Status)
+	}
+	if !gjson.ValidBytes(resp.Body) {
+		return nil, errors.Errorf("GET %s invalid json body", path)
+	}
+	return resp.Body, nil
+}
+
+// GetLogstashHotThreads get the busiest threads of the Logstash JVM
+func GetLogstashHotThreads(rc *ReqClient, path string, params HotThreadsParams, milliseconds int64) (*HotThreadsInfo, error) {
+	reqGet, err := rc.GetQuery(path, params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	hti := &HotThreadsInfo{}
+
+	err = json.Unmarshal(resp.Body, hti)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return hti, nil
+}
+
+// GetLogstashNodePipelines get the settings of every running pipeline
+func GetLogstashNodePipelines(rc *ReqClient, path string, milliseconds int64) (*NodePipelinesInfo, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	npi := &NodePipelinesInfo{}
+
+	err = json.Unmarshal(resp.Body, npi)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return npi, nil
+}
+
+// GetLogstashNodePlugins get the installed plugins and their versions
+func GetLogstashNodePlugins(rc *ReqClient, path string, milliseconds int64) (*NodePluginsInfo, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	npi := &NodePluginsInfo{}
+
+	err = json.Unmarshal(resp.Body, npi)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return npi, nil
+}
+
+// GetLogstashNodeInfo get the os and jvm info of the node
+func GetLogstashNodeInfo(rc *ReqClient, path string, milliseconds int64) (*NodeInfo, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	ni := &NodeInfo{}
+
+	err = json.Unmarshal(resp.Body, ni)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return ni, nil
+}
+
+// GetLogstashNodeLogging get the level of every logger
+func GetLogstashNodeLogging(rc *ReqClient, path string, milliseconds int64) (*NodeLoggingInfo, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	nli := &NodeLoggingInfo{}
+
+	err = json.Unmarshal(resp.Body, nli)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return nli, nil
+}
+
+// GetLogstashHealthReport get the health report of Logstash
+func GetLogstashHealthReport(rc *ReqClient, path string, milliseconds int64) (*HealthReportInfo, error) {
+	reqGet, err := rc.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrAPINotSupported
+	}
+	hri := &HealthReportInfo{}
+
+	err = json.Unmarshal(resp.Body, hri)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return hri, nil
+}
+
+// PipelineGraphParams are the query params asking /_node/stats/pipelines for the pipeline graph and its vertices stats
+type PipelineGraphParams struct {
+	Graph    bool `url:"graph"`
+	Vertices bool `url:"vertices"`
+}
+
+// GetLogstashPipelineGraph get the graph of the pipelines with the stats of every vertex
+func GetLogstashPipelineGraph(rc *ReqClient, path string, milliseconds int64) (*PipelineGraphInfo, error) {
+	reqGet, err := rc.GetQuery(path, PipelineGraphParams{Graph: true, Vertices: true})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := rc.Do(reqGet, time.Duration(milliseconds)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.Errorf("GET %s not found", path)
+	}
+	pgi := &PipelineGraphInfo{}
+
+	err = json.Unmarshal(resp.Body, pgi)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Unmarshal body <%#v> from <%s>", resp.Body, reqGet.RequestURI))
+		return nil, err
+	}
+	return pgi, nil
+}
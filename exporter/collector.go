@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// collectorFactory builds a collector, it returns a nil collector when there is nothing to collect
+type collectorFactory func(e *LogstashExporter) (Collector, error)
+
+// collectorRegistration is a collector known to the exporter with its default state
+type collectorRegistration struct {
+	defaultEnabled bool
+	factory        collectorFactory
+}
+
+// collectorRegistrations are the registered collectors by name, filled in by the init of every collector
+var collectorRegistrations = make(map[string]collectorRegistration)
+
+// registerCollector makes a collector selectable by name, the name must match the Name of the collector
+func registerCollector(name string, defaultEnabled bool, factory collectorFactory) {
+	if _, ok := collectorRegistrations[name]; ok {
+		panic("collector " + name + " registered twice")
+	}
+	collectorRegistrations[name] = collectorRegistration{defaultEnabled: defaultEnabled, factory: factory}
+}
+
+// CollectorDefaults returns the default enabled state of every registered collector by name
+func CollectorDefaults() map[string]bool {
+	defaults := make(map[string]bool, len(collectorRegistrations))
+	for name, registration := range collectorRegistrations {
+		defaults[name] = registration.defaultEnabled
+	}
+	return defaults
+}
+
+// collectorNames returns the sorted names of the registered collectors
+func collectorNames() []string {
+	names := make([]string, 0, len(collectorRegistrations))
+	for name := range collectorRegistrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCollectorNames rejects the names of collectors which are not registered
+func checkCollectorNames(collectors map[string]bool) error {
+	for name := range collectors {
+		if _, ok := collectorRegistrations[name]; !ok {
+			return errors.Errorf("unknown collector <%s>", name)
+		}
+	}
+	return nil
+}
+
+// enabledCollectors builds the collectors enabled in selection, collectors missing from selection use their default
+func enabledCollectors(e *LogstashExporter, selection map[string]bool) ([]Collector, error) {
+	if err := checkCollectorNames(selection); err != nil {
+		return nil, err
+	}
+	var collectors []Collector
+	for _, name := range collectorNames() {
+		registration := collectorRegistrations[name]
+		enabled, ok := selection[name]
+		if !ok {
+			enabled = registration.defaultEnabled
+		}
+		if !enabled {
+			continue
+		}
+		c, err := registration.factory(e)
+		if err != nil {
+			return nil, errors.Wrap(err, "collector "+name)
+		}
+		if c != nil {
+			collectors = append(collectors, c)
+		}
+	}
+	return collectors, nil
+}
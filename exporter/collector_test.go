@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnabledCollectors(t *testing.T) {
+	e := newTestExporter(t, nil)
+
+	tests := []struct {
+		name      string
+		selection map[string]bool
+		rules     []MappingRule
+		expected  []string
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"flow", "health_report", "logging", "node_info", "node_stats", "pipelines", "plugin_stats", "plugins"},
+		},
+		{
+			name:      "selection overrides defaults",
+			selection: map[string]bool{"hot_threads": true, "plugin_stats": false, "flow": true},
+			expected:  []string{"flow", "health_report", "hot_threads", "logging", "node_info", "node_stats", "pipelines", "plugins"},
+		},
+		{
+			name:     "mapping with rules",
+			rules:    []MappingRule{{JSONPath: "jvm.threads.count", Name: "threads"}},
+			expected: []string{"flow", "health_report", "logging", "mapping", "node_info", "node_stats", "pipelines", "plugin_stats", "plugins"},
+		},
+	}
+	for _, tt := range tests {
+		e.options.MappingRules = tt.rules
+		collectors, err := enabledCollectors(e, tt.selection)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		names := make([]string, 0, len(collectors))
+		for _, c := range collectors {
+			names = append(names, c.Name())
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s: got %v, expected %v", tt.name, names, tt.expected)
+		}
+	}
+
+	if _, err := enabledCollectors(e, map[string]bool{"jvm_stats": true}); err == nil {
+		t.Error("expected an error for an unknown collector")
+	}
+}
+
+func TestCollectorNamesMatchRegistrations(t *testing.T) {
+	e := newTestExporter(t, nil)
+	e.options.MappingRules = []MappingRule{{JSONPath: "jvm.threads.count", Name: "threads"}}
+
+	for _, name := range collectorNames() {
+		c, err := collectorRegistrations[name].factory(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Name() != name {
+			t.Errorf("collector registered as %s is named %s", name, c.Name())
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Config is the optional yaml configuration file of the exporter
+type Config struct {
+	// Collectors enables or disables collectors by name, the --collector.<name> flags take precedence
+	Collectors map[string]bool `yaml:"collectors"`
+	Mappings   []MappingRule   `yaml:"mappings"`
+}
+
+// MappingRule turns every value matched by JSONPath in the response of APIPath into a metric.
+//
+// JSONPath is a dotted gjson path where a `*` segment iterates the keys of an object and a `#` segment
+// iterates the elements of an array, for instance: pipelines.*.plugins.filters.#.events.duration_in_millis.
+// Labels maps a label name to either `$N`, the key or index matched by the Nth wildcard, or a gjson path
+// evaluated against the element matched by the last wildcard, so `id` reads the plugin id above.
+type MappingRule struct {
+	APIPath  string            `yaml:"api_path"`
+	JSONPath string            `yaml:"json_path"`
+	Name     string            `yaml:"name"`
+	Help     string            `yaml:"help"`
+	Type     string            `yaml:"type"`
+	Scale    float64           `yaml:"scale"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+// LoadConfig reads and validates the configuration file at path
+func LoadConfig(path string) (*Config, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("read config file <%s>", path))
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("parse config file <%s>", path))
+	}
+	if err := checkCollectorNames(cfg.Collectors); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("config file <%s> collectors", path))
+	}
+	for i := range cfg.Mappings {
+		if err := cfg.Mappings[i].validate(); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("config file <%s> mappings[%d]", path, i))
+		}
+	}
+	return cfg, nil
+}
+
+// validate checks the rule and fills in the defaults
+func (r *MappingRule) validate() error {
+	if r.APIPath == "" {
+		r.APIPath = NodeStatsPath
+	}
+	if !strings.HasPrefix(r.APIPath, "/") {
+		return errors.Errorf("api_path <%s> must start with /", r.APIPath)
+	}
+	if r.JSONPath == "" {
+		return errors.New("json_path is required")
+	}
+	if !metricNameRE.MatchString(r.Name) {
+		return errors.Errorf("invalid metric name <%s>", r.Name)
+	}
+	if r.Help == "" {
+		r.Help = fmt.Sprintf("%s mapped from %s %s", r.Name, r.APIPath, r.JSONPath)
+	}
+	switch r.Type {
+	case "":
+		r.Type = "gauge"
+	case "gauge", "counter":
+	default:
+		return errors.Errorf("invalid type <%s>, must be gauge or counter", r.Type)
+	}
+	if r.Scale == 0 {
+		r.Scale = 1
+	}
+	for name := range r.Labels {
+		if !labelNameRE.MatchString(name) || name == "hostname" || name == "logstash_usage" {
+			return errors.Errorf("invalid label name <%s>", name)
+		}
+	}
+	return nil
+}
@@ -5,16 +5,23 @@
 package exporter
 
 import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 )
 
 const RootPath = "/"
 
+// NodeStatsPath is the node stats api shared by the node_stats, flow and plugin_stats collectors
+const NodeStatsPath = "/_node/stats"
+
 type BuildInfo struct {
 	Version   string
 	CommitSha string
@@ -28,12 +35,26 @@ type Options struct {
 	Hostname                 string
 	MetricsPath              string
 	ScrapeTimeoutMillisecond int64
+	Collectors               map[string]bool // enabled state by collector name, missing collectors use their default
+	FlowWindows              []string
+	PluginDiscoveryAllow     []string
+	PluginDiscoveryDeny      []string
+	MappingRules             []MappingRule
+	HotThreadsCount          int
+	HotThreadsInterval       time.Duration
 	Registry                 *prometheus.Registry
 	BuildInfo                BuildInfo
 }
 
 type Collector interface {
-	Collect(ch chan<- prometheus.Metric)
+	// Name is the name the collector is registered with, it identifies the collector in the collector_success
+	// and collector_duration_seconds metrics
+	Name() string
+	// Describe sends the descriptors of the metrics the collector always knows about, collectors
+	// discovering their metrics at scrape time send nothing
+	Describe(ch chan<- *prometheus.Desc)
+	// Collect sends the metrics of the collector to ch, an error marks the collector as failed for this scrape
+	Collect(ch chan<- prometheus.Metric) error
 }
 
 // LogstashExporter implements the prometheus.Exporter interface, and exports Logstash metrics.
@@ -47,11 +68,17 @@ type LogstashExporter struct {
 	scrapeDuration *prometheus.SummaryVec
 	logstashUp     *prometheus.GaugeVec
 
-	reqClient  *ReqClient
-	collectors []Collector
+	collectorSuccess  *prometheus.Desc
+	collectorDuration *prometheus.Desc
+
+	reqClient   *ReqClient
+	collectors  []Collector
+	scrapeStats *nodeStatsFetch
+	graphs      *graphRenderer
 
 	options   Options
 	mux       *http.ServeMux
+	handler   http.Handler
 	buildInfo BuildInfo
 }
 
@@ -75,38 +102,73 @@ func NewLogstashExporter(opts Options) (*LogstashExporter, error) {
 			Name:      "up",
 			Help:      "Information about the Logstash instance",
 		}, []string{"hostname", "logstash_usage"}),
+		collectorSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, "exporter", "collector_success"),
+			"Whether the collector succeeded during the last scrape",
+			[]string{"collector"},
+			map[string]string{"hostname": opts.Hostname, "logstash_usage": opts.LogstashUsage},
+		),
+		collectorDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, "exporter", "collector_duration_seconds"),
+			"Duration of the collector during the last scrape",
+			[]string{"collector"},
+			map[string]string{"hostname": opts.Hostname, "logstash_usage": opts.LogstashUsage},
+		),
 
 		options:   opts,
 		buildInfo: opts.BuildInfo,
 	}
 
 	e.reqClient = NewReqClient(opts.EndPoint)
+	e.graphs = newGraphRenderer()
 
-	nodeStatCollector, _ := NewNodeStatsCollector(e)
-
-	e.collectors = append(e.collectors, nodeStatCollector)
+	collectors, err := enabledCollectors(e, opts.Collectors)
+	if err != nil {
+		return nil, err
+	}
+	e.collectors = collectors
 
 	e.mux = http.NewServeMux()
 
 	if e.options.Registry != nil {
-		e.options.Registry.MustRegister(e)
-		e.mux.Handle(e.options.MetricsPath, promhttp.HandlerFor(
-			e.options.Registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError},
-		))
+		// the descriptors of every collector are checked here, conflicting mapping rules fail the startup
+		if err := e.options.Registry.Register(e); err != nil {
+			return nil, err
+		}
+		e.handler = promhttp.HandlerFor(e.options.Registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+		e.mux.HandleFunc(e.options.MetricsPath, e.metricsHandler)
 	}
 
 	e.mux.HandleFunc("/", e.indexHandler)
 	e.mux.HandleFunc("/health", e.healthHandler)
+	e.mux.HandleFunc("/graph", e.graphHandler)
+	e.mux.HandleFunc("/probe", e.probeHandler)
 
 	return e, nil
 }
 
-// Describe outputs Redis metric descriptions.
-func (e *LogstashExporter) Describe(_ chan<- *prometheus.Desc) {
+// Describe outputs the descriptions of the exporter metrics and of every collector.
+func (e *LogstashExporter) Describe(ch chan<- *prometheus.Desc) {
+	e.describe(ch, e.collectors)
 }
 
 // Collect fetches new metrics from the RedisHost and updates the appropriate metrics.
 func (e *LogstashExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, e.collectors)
+}
+
+func (e *LogstashExporter) describe(ch chan<- *prometheus.Desc, collectors []Collector) {
+	e.logstashUp.Describe(ch)
+	e.totalScrapes.Describe(ch)
+	e.scrapeDuration.Describe(ch)
+	ch <- e.collectorSuccess
+	ch <- e.collectorDuration
+	for _, c := range collectors {
+		c.Describe(ch)
+	}
+}
+
+func (e *LogstashExporter) collect(ch chan<- prometheus.Metric, collectors []Collector) {
 	e.Lock()
 	defer e.Unlock()
 	e.totalScrapes.WithLabelValues(e.options.Hostname, e.options.LogstashUsage).Inc()
@@ -119,15 +181,17 @@ func (e *LogstashExporter) Collect(ch chan<- prometheus.Metric) {
 		log.Errorf("request %s%s", e.endpoint, RootPath)
 	} else {
 		e.logstashUp.WithLabelValues(rootInfo.Host, e.options.LogstashUsage).Set(1)
+		e.scrapeStats = &nodeStatsFetch{}
 		wg := sync.WaitGroup{}
-		wg.Add(len(e.collectors))
-		for _, c := range e.collectors {
+		wg.Add(len(collectors))
+		for _, c := range collectors {
 			go func(c Collector) {
-				c.Collect(ch)
+				e.execute(c, ch)
 				wg.Done()
 			}(c)
 		}
 		wg.Wait()
+		e.scrapeStats = nil
 		took := time.Since(startTime).Seconds()
 		e.scrapeDuration.WithLabelValues(e.options.Hostname, e.options.LogstashUsage).Observe(took)
 	}
@@ -135,3 +199,134 @@ func (e *LogstashExporter) Collect(ch chan<- prometheus.Metric) {
 	e.totalScrapes.Collect(ch)
 	e.scrapeDuration.Collect(ch)
 }
+
+// nodeStatsFetch shares one /_node/stats response between the collectors of a scrape, the raw body for the
+// json path based collectors and its decoding for the others
+type nodeStatsFetch struct {
+	fetchOnce sync.Once
+	body      []byte
+	fetchErr  error
+
+	decodeOnce sync.Once
+	stats      *NodeStatsInfo
+	decodeErr  error
+}
+
+// nodeStatsBody returns the raw /_node/stats response of the running scrape, requested once whatever the number
+// of collectors reading it, outside of a scrape it is requested on every call
+func (e *LogstashExporter) nodeStatsBody() ([]byte, error) {
+	fetch := e.scrapeStats
+	if fetch == nil {
+		return GetLogstashRaw(e.reqClient, NodeStatsPath, e.options.ScrapeTimeoutMillisecond)
+	}
+	fetch.fetchOnce.Do(func() {
+		fetch.body, fetch.fetchErr = GetLogstashRaw(e.reqClient, NodeStatsPath, e.options.ScrapeTimeoutMillisecond)
+	})
+	return fetch.body, fetch.fetchErr
+}
+
+// nodeStats returns the decoded /_node/stats response of the running scrape, see nodeStatsBody
+func (e *LogstashExporter) nodeStats() (*NodeStatsInfo, error) {
+	fetch := e.scrapeStats
+	if fetch == nil {
+		body, err := e.nodeStatsBody()
+		if err != nil {
+			return nil, err
+		}
+		return decodeNodeStats(body)
+	}
+	fetch.decodeOnce.Do(func() {
+		body, err := e.nodeStatsBody()
+		if err != nil {
+			fetch.decodeErr = err
+			return
+		}
+		fetch.stats, fetch.decodeErr = decodeNodeStats(body)
+	})
+	return fetch.stats, fetch.decodeErr
+}
+
+func decodeNodeStats(body []byte) (*NodeStatsInfo, error) {
+	stats := &NodeStatsInfo{}
+	if err := json.Unmarshal(body, stats); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Unmarshal body from <%s>", NodeStatsPath))
+	}
+	return stats, nil
+}
+
+// execute runs a collector and reports whether it succeeded and how long it took, a failed collector
+// only loses its own metrics
+func (e *LogstashExporter) execute(c Collector, ch chan<- prometheus.Metric) {
+	name := c.Name()
+	begin := time.Now()
+	err := c.Collect(ch)
+	duration := time.Since(begin).Seconds()
+
+	success := float64(1)
+	if err != nil {
+		log.Errorf("collector %s failed after %fs: %s", name, duration, err)
+		success = 0
+	}
+	ch <- prometheus.MustNewConstMetric(e.collectorDuration, prometheus.GaugeValue, duration, name)
+	ch <- prometheus.MustNewConstMetric(e.collectorSuccess, prometheus.GaugeValue, success, name)
+}
+
+// describeFields sends every *prometheus.Desc field of the collector struct c, including the values of
+// map[string]*prometheus.Desc fields, so collectors with fixed metrics do not list their descriptors twice
+func describeFields(c interface{}, ch chan<- *prometheus.Desc) {
+	descType := reflect.TypeOf((*prometheus.Desc)(nil))
+	v := reflect.Indirect(reflect.ValueOf(c))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		switch {
+		case field.Type() == descType:
+			if !field.IsNil() {
+				ch <- field.Interface().(*prometheus.Desc)
+			}
+		case field.Kind() == reflect.Map && field.Type().Elem() == descType:
+			iter := field.MapRange()
+			for iter.Next() {
+				ch <- iter.Value().Interface().(*prometheus.Desc)
+			}
+		}
+	}
+}
+
+// collectorView is the exporter restricted to some of its collectors, used to serve collect[] scrapes
+type collectorView struct {
+	*LogstashExporter
+	collectors []Collector
+}
+
+func (v collectorView) Describe(ch chan<- *prometheus.Desc) {
+	v.describe(ch, v.collectors)
+}
+
+func (v collectorView) Collect(ch chan<- prometheus.Metric) {
+	v.collect(ch, v.collectors)
+}
+
+// selectCollectors returns the enabled collectors with the given names, in the order of the exporter collectors
+func (e *LogstashExporter) selectCollectors(names []string) ([]Collector, error) {
+	enabled := make(map[string]bool, len(e.collectors))
+	for _, c := range e.collectors {
+		enabled[c.Name()] = true
+	}
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !enabled[name] {
+			return nil, errors.Errorf("collector <%s> is unknown or disabled", name)
+		}
+		selected[name] = true
+	}
+	var collectors []Collector
+	for _, c := range e.collectors {
+		if selected[c.Name()] {
+			collectors = append(collectors, c)
+		}
+	}
+	return collectors, nil
+}
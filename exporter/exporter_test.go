@@ -0,0 +1,175 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// collectorAdapter turns an exporter Collector into a prometheus.Collector for testutil
+type collectorAdapter struct {
+	Collector
+}
+
+// Collect drops the error, the tests compare the collected metrics
+func (a collectorAdapter) Collect(ch chan<- prometheus.Metric) {
+	_ = a.Collector.Collect(ch)
+}
+
+// newTestExporter starts a fake logstash serving the given fixture files keyed by request path
+func newTestExporter(t *testing.T, fixtures map[string]string) *LogstashExporter {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := ioutil.ReadFile(fixture)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	e, err := NewLogstashExporter(Options{
+		Namespace:                "logstash",
+		EndPoint:                 srv.URL,
+		LogstashUsage:            "logstash",
+		Hostname:                 "test",
+		MetricsPath:              "/metrics",
+		ScrapeTimeoutMillisecond: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestLogstashExporterCollectorSuccess(t *testing.T) {
+	e := newTestExporter(t, map[string]string{
+		"/":            "testdata/root.json",
+		"/_node/stats": "testdata/node_stats.json",
+	})
+
+	// the other apis are missing from the fake logstash, the health report is skipped on 404
+	expected := `
+# HELP logstash_exporter_collector_success Whether the collector succeeded during the last scrape
+# TYPE logstash_exporter_collector_success gauge
+logstash_exporter_collector_success{collector="flow",hostname="test",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="health_report",hostname="test",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="logging",hostname="test",logstash_usage="logstash"} 0
+logstash_exporter_collector_success{collector="node_info",hostname="test",logstash_usage="logstash"} 0
+logstash_exporter_collector_success{collector="node_stats",hostname="test",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="pipelines",hostname="test",logstash_usage="logstash"} 0
+logstash_exporter_collector_success{collector="plugin_stats",hostname="test",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="plugins",hostname="test",logstash_usage="logstash"} 0
+`
+	err := testutil.CollectAndCompare(e, strings.NewReader(expected), "logstash_exporter_collector_success")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// lintAllowed are the lint problems of metric names kept for compatibility with existing dashboards
+var lintAllowed = map[string]bool{
+	"logstash_node_stats_gc_collection_total":    true,
+	"logstash_node_stats_jvm_threads_count":      true,
+	"logstash_node_stats_jvm_threads_peak_count": true,
+}
+
+func TestCollectorsLint(t *testing.T) {
+	// node stats fixtures of the collectors reading parts of /_node/stats missing from node_stats.json
+	statsFixtures := map[string]string{
+		"flow":         "testdata/node_stats_flow.json",
+		"plugin_stats": "testdata/node_stats_pipelines.json",
+	}
+
+	for _, name := range collectorNames() {
+		fixtures := map[string]string{
+			"/_node/stats":           "testdata/node_stats.json",
+			"/_node/stats/pipelines": "testdata/node_stats_pipelines.json",
+			"/_node/pipelines":       "testdata/node_pipelines.json",
+			"/_node/plugins":         "testdata/node_plugins.json",
+			"/_health_report":        "testdata/health_report.json",
+			"/_node/os,jvm":          "testdata/node_info.json",
+			"/_node/logging":         "testdata/node_logging.json",
+		}
+		if fixture, ok := statsFixtures[name]; ok {
+			fixtures["/_node/stats"] = fixture
+		}
+		for _, c := range newTestExporter(t, fixtures).collectors {
+			if c.Name() != name {
+				continue
+			}
+			problems, err := testutil.CollectAndLint(collectorAdapter{c})
+			if err != nil {
+				t.Errorf("collector %s: %s", name, err)
+				continue
+			}
+			for _, problem := range problems {
+				if !lintAllowed[problem.Metric] {
+					t.Errorf("collector %s: %s %s", name, problem.Metric, problem.Text)
+				}
+			}
+		}
+	}
+}
+
+func TestNewLogstashExporterConflictingMapping(t *testing.T) {
+	_, err := NewLogstashExporter(Options{
+		Namespace:     "logstash",
+		EndPoint:      "http://localhost:9600",
+		LogstashUsage: "logstash",
+		Hostname:      "test",
+		MetricsPath:   "/metrics",
+		MappingRules: []MappingRule{
+			{JSONPath: "events.in", Name: "node_stats_events_in_total", Type: "counter"},
+		},
+		Registry: prometheus.NewRegistry(),
+	})
+	if err == nil {
+		t.Error("expected an error for a mapping rule conflicting with logstash_node_stats_events_in_total")
+	}
+}
+
+func TestLogstashExporterSharesNodeStats(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture := "testdata/root.json"
+		if r.URL.Path == NodeStatsPath {
+			atomic.AddInt32(&requests, 1)
+			fixture = "testdata/node_stats.json"
+		}
+		body, _ := ioutil.ReadFile(fixture)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	e, err := NewLogstashExporter(Options{
+		Namespace:                "logstash",
+		EndPoint:                 srv.URL,
+		LogstashUsage:            "logstash",
+		Hostname:                 "test",
+		MetricsPath:              "/metrics",
+		ScrapeTimeoutMillisecond: 1000,
+		MappingRules:             []MappingRule{{JSONPath: "jvm.threads.count", Name: "threads"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		testutil.CollectAndCount(e)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d node stats requests for 2 scrapes, expected 2", n)
+	}
+}
@@ -0,0 +1,142 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// FlowWindows lists the windows reported by Logstash for every flow metric
+	FlowWindows = []string{"current", "last_1_minute", "last_5_minutes", "last_15_minutes", "last_1_hour", "last_24_hours", "lifetime"}
+
+	nodeFlowNames     = []string{"input_throughput", "filter_throughput", "output_throughput", "queue_backpressure", "worker_concurrency"}
+	pipelineFlowNames = []string{"input_throughput", "filter_throughput", "output_throughput", "queue_backpressure", "worker_concurrency", "worker_utilization", "queue_persisted_growth_events", "queue_persisted_growth_bytes"}
+	pluginFlowNames   = []string{"throughput", "worker_utilization", "worker_millis_per_event"}
+)
+
+// FlowCollector exports the Logstash >=8.5 flow metrics at node, pipeline and plugin level
+type FlowCollector struct {
+	export *LogstashExporter
+
+	windows map[string]bool
+
+	NodeFlows     map[string]*prometheus.Desc
+	PipelineFlows map[string]*prometheus.Desc
+	PluginFlows   map[string]*prometheus.Desc
+}
+
+func init() {
+	registerCollector("flow", true, func(e *LogstashExporter) (Collector, error) {
+		return NewFlowCollector(e)
+	})
+}
+
+func NewFlowCollector(e *LogstashExporter) (*FlowCollector, error) {
+	const subsystem = "flow"
+	c := &FlowCollector{
+		export: e,
+
+		windows: make(map[string]bool),
+
+		NodeFlows:     make(map[string]*prometheus.Desc),
+		PipelineFlows: make(map[string]*prometheus.Desc),
+		PluginFlows:   make(map[string]*prometheus.Desc),
+	}
+
+	windows := e.options.FlowWindows
+	if len(windows) == 0 {
+		windows = FlowWindows
+	}
+	known := make(map[string]bool, len(FlowWindows))
+	for _, window := range FlowWindows {
+		known[window] = true
+	}
+	for _, window := range windows {
+		if !known[window] {
+			return nil, errors.Errorf("unknown flow window <%s>", window)
+		}
+		c.windows[window] = true
+	}
+
+	for _, name := range nodeFlowNames {
+		c.NodeFlows[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, name),
+			name,
+			[]string{"window"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		)
+	}
+	for _, name := range pipelineFlowNames {
+		c.PipelineFlows[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_"+name),
+			"pipeline_"+name,
+			[]string{"pipeline", "window"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		)
+	}
+	for _, name := range pluginFlowNames {
+		c.PluginFlows[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "plugin_"+name),
+			"plugin_"+name,
+			[]string{"pipeline", "plugin_type", "plugin", "plugin_id", "window"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		)
+	}
+	return c, nil
+}
+
+func (c *FlowCollector) Name() string {
+	return "flow"
+}
+
+func (c *FlowCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *FlowCollector) Collect(ch chan<- prometheus.Metric) error {
+	stats, err := c.export.nodeStats()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodeStats <%s>", NodeStatsPath))
+	}
+
+	c.collectFlows(ch, c.NodeFlows, stats.Flow)
+
+	for pipelineID, pipeline := range stats.Pipelines {
+		c.collectFlows(ch, c.PipelineFlows, pipeline.Flow, pipelineID)
+
+		for _, plugin := range pipeline.Plugins.Inputs {
+			c.collectFlows(ch, c.PluginFlows, plugin.Flow, pipelineID, "input", plugin.Name, plugin.ID)
+		}
+		for _, plugin := range pipeline.Plugins.Filters {
+			c.collectFlows(ch, c.PluginFlows, plugin.Flow, pipelineID, "filter", plugin.Name, plugin.ID)
+		}
+		for _, plugin := range pipeline.Plugins.Outputs {
+			c.collectFlows(ch, c.PluginFlows, plugin.Flow, pipelineID, "output", plugin.Name, plugin.ID)
+		}
+	}
+	return nil
+}
+
+// collectFlows emits one gauge per known flow and selected window, the window label is appended to labelValues
+func (c *FlowCollector) collectFlows(ch chan<- prometheus.Metric, descs map[string]*prometheus.Desc, flows FlowMetrics, labelValues ...string) {
+	for name, values := range flows {
+		desc, ok := descs[name]
+		if !ok {
+			log.Debugf("skip unknown flow metric <%s>", name)
+			continue
+		}
+		for window, value := range values {
+			if !c.windows[window] {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				desc,
+				prometheus.GaugeValue,
+				value,
+				append(labelValues, window)...,
+			)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"encoding/json"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlowCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats_flow.json"})
+	e.options.FlowWindows = []string{"current"}
+	c, _ := NewFlowCollector(e)
+
+	expected := `
+# HELP logstash_flow_input_throughput input_throughput
+# TYPE logstash_flow_input_throughput gauge
+logstash_flow_input_throughput{hostname="test",logstash_usage="logstash",window="current"} 12.5
+# HELP logstash_flow_pipeline_worker_utilization pipeline_worker_utilization
+# TYPE logstash_flow_pipeline_worker_utilization gauge
+logstash_flow_pipeline_worker_utilization{hostname="test",logstash_usage="logstash",pipeline="main",window="current"} 30.5
+# HELP logstash_flow_plugin_throughput plugin_throughput
+# TYPE logstash_flow_plugin_throughput gauge
+logstash_flow_plugin_throughput{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in",plugin_type="input",window="current"} 12.5
+# HELP logstash_flow_worker_concurrency worker_concurrency
+# TYPE logstash_flow_worker_concurrency gauge
+logstash_flow_worker_concurrency{hostname="test",logstash_usage="logstash",window="current"} 1.2
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFlowMetricsUnmarshalSkipsNonNumbers(t *testing.T) {
+	body := `{
+  "version": "8.15.3",
+  "flow": {
+    "input_throughput": {"current": 12.5, "lifetime": null, "last_1_minute": "n/a"},
+    "worker_concurrency": null,
+    "queue_backpressure": "unsupported"
+  },
+  "events": {"in": 10}
+}`
+	stats := &NodeStatsInfo{}
+	if err := json.Unmarshal([]byte(body), stats); err != nil {
+		t.Fatal(err)
+	}
+	expected := FlowMetrics{"input_throughput": {"current": 12.5}}
+	if !reflect.DeepEqual(stats.Flow, expected) {
+		t.Errorf("got %v, expected %v", stats.Flow, expected)
+	}
+	if stats.Events.In != 10 {
+		t.Errorf("events in %d, expected 10", stats.Events.In)
+	}
+}
+
+func TestNewFlowCollectorUnknownWindow(t *testing.T) {
+	e := newTestExporter(t, nil)
+	e.options.FlowWindows = []string{"current", "last_1_minutes"}
+	if _, err := NewFlowCollector(e); err == nil {
+		t.Error("expected an error for an unknown flow window")
+	}
+}
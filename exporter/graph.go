@@ -0,0 +1,246 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GraphVertex is a plugin, conditional or queue of the pipeline graph
+type GraphVertex struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"` // plugin, if or queue
+	ExplicitID bool   `json:"explicit_id"`
+	ConfigName string `json:"config_name"`
+	PluginType string `json:"plugin_type"`
+	Condition  string `json:"condition"`
+	Meta       *struct {
+		Source struct {
+			Protocol string `json:"protocol"`
+			ID       string `json:"id"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+		} `json:"source"`
+	} `json:"meta"`
+}
+
+// GraphEdge links two vertices, boolean edges leave a conditional for its true or false branch
+type GraphEdge struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"` // plain or boolean
+	When *bool  `json:"when"`
+}
+
+// VertexStats are the events counters of a vertex
+type VertexStats struct {
+	ID                        string `json:"id"`
+	EventsIn                  int64  `json:"events_in"`
+	EventsOut                 int64  `json:"events_out"`
+	DurationInMillis          int64  `json:"duration_in_millis"`
+	QueuePushDurationInMillis int64  `json:"queue_push_duration_in_millis"`
+}
+
+// PipelineGraph is the graph of a pipeline with the stats of its vertices
+type PipelineGraph struct {
+	Vertices []VertexStats `json:"vertices"`
+	Graph    struct {
+		Hash  string `json:"hash"`
+		Graph struct {
+			Vertices []GraphVertex `json:"vertices"`
+			Edges    []GraphEdge   `json:"edges"`
+		} `json:"graph"`
+	} `json:"graph"`
+}
+
+// PipelineGraphInfo type
+type PipelineGraphInfo struct {
+	Host      string                   `json:"host"`
+	Version   string                   `json:"version"`
+	Pipelines map[string]PipelineGraph `json:"pipelines"`
+}
+
+// vertexSample is the events out counter of a vertex at the time the graph was rendered
+type vertexSample struct {
+	eventsOut int64
+	at        time.Time
+}
+
+// annotatedVertex is a vertex with the labels rendered in DOT and Mermaid
+type annotatedVertex struct {
+	GraphVertex
+	lines []string
+}
+
+// annotatedPipeline is a pipeline graph ready to be rendered
+type annotatedPipeline struct {
+	id       string
+	vertices []annotatedVertex
+	edges    []GraphEdge
+}
+
+// graphRenderer renders pipeline graphs, event rates are computed against the previous render of the same vertex,
+// a pipeline keeps the samples of the vertices of its last render so removed vertices are forgotten
+type graphRenderer struct {
+	sync.Mutex
+	// samples by pipeline id and vertex id
+	samples map[string]map[string]vertexSample
+}
+
+func newGraphRenderer() *graphRenderer {
+	return &graphRenderer{samples: make(map[string]map[string]vertexSample)}
+}
+
+// annotate sorts the pipelines and attaches event counts, rates and durations to their vertices,
+// info holds every pipeline when all is set, the samples of the pipelines missing from it are then forgotten
+func (g *graphRenderer) annotate(info *PipelineGraphInfo, now time.Time, all bool) []annotatedPipeline {
+	g.Lock()
+	defer g.Unlock()
+
+	pipelineIDs := make([]string, 0, len(info.Pipelines))
+	for pipelineID := range info.Pipelines {
+		pipelineIDs = append(pipelineIDs, pipelineID)
+	}
+	sort.Strings(pipelineIDs)
+
+	if all {
+		for pipelineID := range g.samples {
+			if _, ok := info.Pipelines[pipelineID]; !ok {
+				delete(g.samples, pipelineID)
+			}
+		}
+	}
+
+	pipelines := make([]annotatedPipeline, 0, len(pipelineIDs))
+	for _, pipelineID := range pipelineIDs {
+		pipeline := info.Pipelines[pipelineID]
+		previousSamples := g.samples[pipelineID]
+		samples := make(map[string]vertexSample, len(pipeline.Vertices))
+
+		stats := make(map[string]VertexStats, len(pipeline.Vertices))
+		var totalDuration int64
+		for _, vertex := range pipeline.Vertices {
+			stats[vertex.ID] = vertex
+			totalDuration += vertex.DurationInMillis
+		}
+
+		ap := annotatedPipeline{id: pipelineID, edges: pipeline.Graph.Graph.Edges}
+		for _, vertex := range pipeline.Graph.Graph.Vertices {
+			av := annotatedVertex{GraphVertex: vertex}
+			switch vertex.Type {
+			case "if":
+				av.lines = append(av.lines, "if "+vertex.Condition)
+			case "queue":
+				av.lines = append(av.lines, "queue")
+			default:
+				av.lines = append(av.lines, fmt.Sprintf("%s %s", vertex.PluginType, vertex.ConfigName))
+				if vertex.ExplicitID {
+					av.lines = append(av.lines, "id: "+vertex.ID)
+				}
+			}
+			if vertex.Meta != nil && vertex.Meta.Source.Line > 0 {
+				av.lines = append(av.lines, fmt.Sprintf("line %d", vertex.Meta.Source.Line))
+			}
+
+			if vs, ok := stats[vertex.ID]; ok {
+				av.lines = append(av.lines, fmt.Sprintf("in %d / out %d", vs.EventsIn, vs.EventsOut))
+
+				if previous, ok := previousSamples[vertex.ID]; ok && now.After(previous.at) && vs.EventsOut >= previous.eventsOut {
+					rate := float64(vs.EventsOut-previous.eventsOut) / now.Sub(previous.at).Seconds()
+					av.lines = append(av.lines, fmt.Sprintf("%.1f events/s", rate))
+				}
+				samples[vertex.ID] = vertexSample{eventsOut: vs.EventsOut, at: now}
+
+				if vs.EventsOut > 0 && vs.DurationInMillis > 0 {
+					av.lines = append(av.lines, fmt.Sprintf("%.3f ms/event", float64(vs.DurationInMillis)/float64(vs.EventsOut)))
+				}
+				if totalDuration > 0 && vs.DurationInMillis > 0 {
+					av.lines = append(av.lines, fmt.Sprintf("%.1f%% of worker time", float64(vs.DurationInMillis)*100/float64(totalDuration)))
+				}
+			}
+			ap.vertices = append(ap.vertices, av)
+		}
+		g.samples[pipelineID] = samples
+		pipelines = append(pipelines, ap)
+	}
+	return pipelines
+}
+
+// edgeLabel is the branch of a boolean edge
+func edgeLabel(edge GraphEdge) string {
+	if edge.Type != "boolean" || edge.When == nil {
+		return ""
+	}
+	return fmt.Sprintf("%t", *edge.When)
+}
+
+// renderDOT renders one graphviz digraph per pipeline
+func renderDOT(pipelines []annotatedPipeline) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	var b strings.Builder
+	for _, pipeline := range pipelines {
+		fmt.Fprintf(&b, "digraph \"%s\" {\n", escape.Replace(pipeline.id))
+		b.WriteString("  rankdir=TB;\n")
+		for _, vertex := range pipeline.vertices {
+			shape := "box"
+			switch vertex.Type {
+			case "if":
+				shape = "diamond"
+			case "queue":
+				shape = "cylinder"
+			}
+			fmt.Fprintf(&b, "  \"%s\" [shape=%s, label=\"%s\"];\n",
+				escape.Replace(vertex.ID), shape, escape.Replace(strings.Join(vertex.lines, "\n")))
+		}
+		for _, edge := range pipeline.edges {
+			fmt.Fprintf(&b, "  \"%s\" -> \"%s\"", escape.Replace(edge.From), escape.Replace(edge.To))
+			if label := edgeLabel(edge); label != "" {
+				fmt.Fprintf(&b, " [label=\"%s\"]", label)
+			}
+			b.WriteString(";\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// renderMermaid renders a single flowchart with one subgraph per pipeline
+func renderMermaid(pipelines []annotatedPipeline) string {
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+	var b strings.Builder
+	b.WriteString("flowchart TB\n")
+	for p, pipeline := range pipelines {
+		// vertex ids are hashes or user defined ids, mermaid gets short generated ones
+		ids := make(map[string]string, len(pipeline.vertices))
+		fmt.Fprintf(&b, "  subgraph p%d[\"%s\"]\n", p, escape.Replace(pipeline.id))
+		for v, vertex := range pipeline.vertices {
+			id := fmt.Sprintf("p%dv%d", p, v)
+			ids[vertex.ID] = id
+			label := escape.Replace(strings.Join(vertex.lines, "\n"))
+			switch vertex.Type {
+			case "if":
+				fmt.Fprintf(&b, "    %s{\"%s\"}\n", id, label)
+			case "queue":
+				fmt.Fprintf(&b, "    %s[(\"%s\")]\n", id, label)
+			default:
+				fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
+			}
+		}
+		for _, edge := range pipeline.edges {
+			from, to := ids[edge.From], ids[edge.To]
+			if from == "" || to == "" {
+				continue
+			}
+			if label := edgeLabel(edge); label != "" {
+				fmt.Fprintf(&b, "    %s -->|%s| %s\n", from, label, to)
+			} else {
+				fmt.Fprintf(&b, "    %s --> %s\n", from, to)
+			}
+		}
+		b.WriteString("  end\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraphRendererRates(t *testing.T) {
+	pipeline := PipelineGraph{Vertices: []VertexStats{{ID: "es_out", EventsIn: 100, EventsOut: 100}}}
+	pipeline.Graph.Graph.Vertices = []GraphVertex{{ID: "es_out", Type: "plugin", ConfigName: "elasticsearch", PluginType: "output"}}
+	info := &PipelineGraphInfo{Pipelines: map[string]PipelineGraph{"main": pipeline}}
+
+	g := newGraphRenderer()
+	now := time.Now()
+	first := g.annotate(info, now, true)
+	if lines := first[0].vertices[0].lines; len(lines) != 2 {
+		t.Errorf("expected no rate on the first render, got %q", lines)
+	}
+
+	pipeline.Vertices[0].EventsOut = 400
+	second := g.annotate(info, now.Add(10*time.Second), true)
+	lines := second[0].vertices[0].lines
+	if lines[len(lines)-1] != "30.0 events/s" {
+		t.Errorf("unexpected rate annotation %q", lines)
+	}
+}
+
+func TestGraphRendererForgetsRemovedVertices(t *testing.T) {
+	pipeline := PipelineGraph{Vertices: []VertexStats{{ID: "es_out"}, {ID: "stdout_out"}}}
+	pipeline.Graph.Graph.Vertices = []GraphVertex{{ID: "es_out"}, {ID: "stdout_out"}}
+	info := &PipelineGraphInfo{Pipelines: map[string]PipelineGraph{"main": pipeline, "syslog": pipeline}}
+
+	g := newGraphRenderer()
+	g.annotate(info, time.Now(), true)
+	if len(g.samples["main"]) != 2 || len(g.samples["syslog"]) != 2 {
+		t.Fatalf("expected 2 samples per pipeline, got %v", g.samples)
+	}
+
+	pipeline.Graph.Graph.Vertices = pipeline.Graph.Graph.Vertices[:1]
+	info.Pipelines = map[string]PipelineGraph{"main": pipeline}
+	g.annotate(info, time.Now(), true)
+	if _, ok := g.samples["main"]["es_out"]; len(g.samples) != 1 || len(g.samples["main"]) != 1 || !ok {
+		t.Errorf("expected only the es_out sample of main, got %v", g.samples)
+	}
+}
+
+func TestGraphRendererKeepsOtherPipelines(t *testing.T) {
+	newInfo := func(pipelineID string, eventsOut int64) *PipelineGraphInfo {
+		pipeline := PipelineGraph{Vertices: []VertexStats{{ID: "es_out", EventsOut: eventsOut}}}
+		pipeline.Graph.Graph.Vertices = []GraphVertex{{ID: "es_out", Type: "plugin", ConfigName: "elasticsearch", PluginType: "output"}}
+		return &PipelineGraphInfo{Pipelines: map[string]PipelineGraph{pipelineID: pipeline}}
+	}
+
+	// single pipeline renders, as done by /graph?pipeline=a, do not forget the other pipelines
+	g := newGraphRenderer()
+	now := time.Now()
+	g.annotate(newInfo("a", 100), now, false)
+	g.annotate(newInfo("b", 100), now.Add(5*time.Second), false)
+	second := g.annotate(newInfo("a", 300), now.Add(10*time.Second), false)
+	lines := second[0].vertices[0].lines
+	if lines[len(lines)-1] != "20.0 events/s" {
+		t.Errorf("expected a rate on the second render of a, got %q", lines)
+	}
+}
@@ -0,0 +1,181 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// healthStatusValues maps the health report status colors to gauge values, higher is worse
+var healthStatusValues = map[string]float64{
+	"unknown": 0,
+	"green":   1,
+	"yellow":  2,
+	"red":     3,
+}
+
+// HealthReportCollector exports the health report of Logstash >=8.16, nothing is exported for older versions
+type HealthReportCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	Status                *prometheus.Desc
+	IndicatorStatus       *prometheus.Desc
+	IndicatorSymptomInfo  *prometheus.Desc
+	PipelineStatus        *prometheus.Desc
+	PipelineSymptomInfo   *prometheus.Desc
+	PipelineDiagnosisInfo *prometheus.Desc
+	PipelineImpact        *prometheus.Desc
+}
+
+func init() {
+	registerCollector("health_report", true, func(e *LogstashExporter) (Collector, error) {
+		return NewHealthReportCollector(e)
+	})
+}
+
+func NewHealthReportCollector(e *LogstashExporter) (*HealthReportCollector, error) {
+	const subsystem = "health_report"
+	return &HealthReportCollector{
+		export:  e,
+		ReqPath: "/_health_report",
+
+		Status: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "status"),
+			"status",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		IndicatorStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "indicator_status"),
+			"indicator_status",
+			[]string{"indicator"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		IndicatorSymptomInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "indicator_symptom_info"),
+			"indicator_symptom_info",
+			[]string{"indicator", "symptom"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_status"),
+			"pipeline_status",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineSymptomInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_symptom_info"),
+			"pipeline_symptom_info",
+			[]string{"pipeline", "symptom"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineDiagnosisInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_diagnosis_info"),
+			"pipeline_diagnosis_info",
+			[]string{"pipeline", "id", "cause"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineImpact: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_impact_severity"),
+			"pipeline_impact_severity",
+			[]string{"pipeline", "id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *HealthReportCollector) Name() string {
+	return "health_report"
+}
+
+func (c *HealthReportCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *HealthReportCollector) Collect(ch chan<- prometheus.Metric) error {
+	report, err := GetLogstashHealthReport(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err == ErrAPINotSupported {
+		log.Debugf("GetLogstashHealthReport <%s>: %s", c.ReqPath, err)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashHealthReport <%s>", c.ReqPath))
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Status,
+		prometheus.GaugeValue,
+		healthStatusValues[report.Status],
+	)
+
+	for name, indicator := range report.Indicators {
+		ch <- prometheus.MustNewConstMetric(
+			c.IndicatorStatus,
+			prometheus.GaugeValue,
+			healthStatusValues[indicator.Status],
+			name,
+		)
+
+		if indicator.Symptom != "" {
+			ch <- prometheus.MustNewConstMetric(
+				c.IndicatorSymptomInfo,
+				prometheus.GaugeValue,
+				float64(1),
+				name, sanitizeErrorMessage(indicator.Symptom),
+			)
+		}
+
+		if name != "pipelines" {
+			continue
+		}
+		for pipelineID, pipeline := range indicator.Indicators {
+			c.collectPipeline(ch, pipelineID, pipeline)
+		}
+	}
+	return nil
+}
+
+func (c *HealthReportCollector) collectPipeline(ch chan<- prometheus.Metric, pipelineID string, pipeline HealthIndicator) {
+	ch <- prometheus.MustNewConstMetric(
+		c.PipelineStatus,
+		prometheus.GaugeValue,
+		healthStatusValues[pipeline.Status],
+		pipelineID,
+	)
+
+	if pipeline.Symptom != "" {
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineSymptomInfo,
+			prometheus.GaugeValue,
+			float64(1),
+			pipelineID, sanitizeErrorMessage(pipeline.Symptom),
+		)
+	}
+
+	for _, diagnosis := range pipeline.Diagnosis {
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineDiagnosisInfo,
+			prometheus.GaugeValue,
+			float64(1),
+			pipelineID, diagnosis.ID, sanitizeErrorMessage(diagnosis.Cause),
+		)
+	}
+
+	// logstash ranks the impact severity from 1, the most severe
+	for _, impact := range pipeline.Impacts {
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineImpact,
+			prometheus.GaugeValue,
+			float64(impact.Severity),
+			pipelineID, impact.ID,
+		)
+	}
+}
@@ -0,0 +1,46 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestHealthReportCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_health_report": "testdata/health_report.json"})
+	c, _ := NewHealthReportCollector(e)
+
+	expected := `
+# HELP logstash_health_report_indicator_status indicator_status
+# TYPE logstash_health_report_indicator_status gauge
+logstash_health_report_indicator_status{hostname="test",indicator="pipelines",logstash_usage="logstash"} 2
+# HELP logstash_health_report_pipeline_impact_severity pipeline_impact_severity
+# TYPE logstash_health_report_pipeline_impact_severity gauge
+logstash_health_report_pipeline_impact_severity{hostname="test",id="logstash:health:pipeline:flow:impact:blocked_processing",logstash_usage="logstash",pipeline="syslog"} 2
+# HELP logstash_health_report_pipeline_status pipeline_status
+# TYPE logstash_health_report_pipeline_status gauge
+logstash_health_report_pipeline_status{hostname="test",logstash_usage="logstash",pipeline="main"} 1
+logstash_health_report_pipeline_status{hostname="test",logstash_usage="logstash",pipeline="syslog"} 2
+# HELP logstash_health_report_status status
+# TYPE logstash_health_report_status gauge
+logstash_health_report_status{hostname="test",logstash_usage="logstash"} 2
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_health_report_indicator_status",
+		"logstash_health_report_pipeline_impact_severity",
+		"logstash_health_report_pipeline_status",
+		"logstash_health_report_status",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHealthReportCollectorNotSupported(t *testing.T) {
+	e := newTestExporter(t, nil)
+	c, _ := NewHealthReportCollector(e)
+
+	if count := testutil.CollectAndCount(collectorAdapter{c}); count != 0 {
+		t.Errorf("expected no metrics from a Logstash without health report, got %d", count)
+	}
+}
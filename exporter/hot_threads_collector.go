@@ -0,0 +1,126 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHotThreadsCount is the number of busiest threads requested when no count is configured
+	DefaultHotThreadsCount = 5
+	// DefaultHotThreadsInterval is the minimum interval between two requests when no interval is configured
+	DefaultHotThreadsInterval = time.Minute
+)
+
+// HotThreadsCollector exports the busiest JVM threads, /_node/hot_threads samples the JVM for a while,
+// so it is requested at most once per interval and the last result is exported in between,
+// a failed request is not retried before the interval either, its error is reported along the stale result
+type HotThreadsCollector struct {
+	sync.Mutex
+
+	export   *LogstashExporter
+	ReqPath  string
+	params   HotThreadsParams
+	interval time.Duration
+
+	lastAttempt time.Time
+	lastFetch   time.Time
+	lastErr     error
+	hotThreads  *HotThreadsInfo
+
+	ThreadCPUPercent   *prometheus.Desc
+	LastFetchTimestamp *prometheus.Desc
+}
+
+func init() {
+	registerCollector("hot_threads", false, func(e *LogstashExporter) (Collector, error) {
+		return NewHotThreadsCollector(e)
+	})
+}
+
+func NewHotThreadsCollector(e *LogstashExporter) (*HotThreadsCollector, error) {
+	const subsystem = "hot_threads"
+	threads := e.options.HotThreadsCount
+	if threads <= 0 {
+		threads = DefaultHotThreadsCount
+	}
+	interval := e.options.HotThreadsInterval
+	if interval <= 0 {
+		interval = DefaultHotThreadsInterval
+	}
+	return &HotThreadsCollector{
+		export:   e,
+		ReqPath:  "/_node/hot_threads",
+		params:   HotThreadsParams{Human: false, Threads: threads},
+		interval: interval,
+
+		ThreadCPUPercent: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "cpu_percent"),
+			"cpu_percent",
+			[]string{"thread_name", "state"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		LastFetchTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "last_fetch_timestamp_seconds"),
+			"last_fetch_timestamp_seconds",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *HotThreadsCollector) Name() string {
+	return "hot_threads"
+}
+
+func (c *HotThreadsCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *HotThreadsCollector) Collect(ch chan<- prometheus.Metric) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.lastAttempt.IsZero() || time.Since(c.lastAttempt) >= c.interval {
+		c.lastAttempt = time.Now()
+		hotThreads, err := GetLogstashHotThreads(c.export.reqClient, c.ReqPath, c.params, c.export.options.ScrapeTimeoutMillisecond)
+		if err != nil {
+			c.lastErr = errors.Wrap(err, fmt.Sprintf("GetLogstashHotThreads <%s>", c.ReqPath))
+		} else {
+			c.hotThreads = hotThreads
+			c.lastFetch = c.lastAttempt
+			c.lastErr = nil
+		}
+	}
+
+	if c.hotThreads == nil {
+		return c.lastErr
+	}
+
+	// thread names are not unique, the busiest thread of a name and state wins
+	seen := make(map[string]bool)
+	for _, thread := range c.hotThreads.HotThreads.Threads {
+		id := thread.Name + "\xff" + thread.State
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ch <- prometheus.MustNewConstMetric(
+			c.ThreadCPUPercent,
+			prometheus.GaugeValue,
+			thread.PercentOfCPUTime,
+			thread.Name, thread.State,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.LastFetchTimestamp,
+		prometheus.GaugeValue,
+		float64(c.lastFetch.UnixNano())/1e9,
+	)
+	return c.lastErr
+}
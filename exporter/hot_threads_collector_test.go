@@ -0,0 +1,82 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHotThreadsCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/hot_threads": "testdata/hot_threads.json"})
+	e.options.HotThreadsCount = 3
+	e.options.HotThreadsInterval = time.Hour
+	c, _ := NewHotThreadsCollector(e)
+
+	expected := `
+# HELP logstash_hot_threads_cpu_percent cpu_percent
+# TYPE logstash_hot_threads_cpu_percent gauge
+logstash_hot_threads_cpu_percent{hostname="test",logstash_usage="logstash",state="runnable",thread_name="[main]>worker0"} 12.5
+logstash_hot_threads_cpu_percent{hostname="test",logstash_usage="logstash",state="timed_waiting",thread_name="[main]>worker1"} 8.25
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected), "logstash_hot_threads_cpu_percent")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// within the interval the cached hot threads are exported without requesting logstash
+	e.reqClient.BaseUrl = "http://127.0.0.1:1"
+	err = testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected), "logstash_hot_threads_cpu_percent")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHotThreadsCollectorFailure(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/hot_threads": "testdata/hot_threads.json"})
+	e.options.HotThreadsInterval = time.Hour
+	c, _ := NewHotThreadsCollector(e)
+	baseUrl := e.reqClient.BaseUrl
+
+	collect := func() (int, error) {
+		ch := make(chan prometheus.Metric, 10)
+		err := c.Collect(ch)
+		close(ch)
+		return len(ch), err
+	}
+
+	if n, err := collect(); err != nil || n != 3 {
+		t.Fatalf("expected 3 metrics and no error, got %d, %v", n, err)
+	}
+
+	// a failed request reports its error and still exports the stale hot threads
+	c.lastAttempt = c.lastAttempt.Add(-2 * time.Hour)
+	e.reqClient.BaseUrl = "http://127.0.0.1:1"
+	if n, err := collect(); err == nil || n != 3 {
+		t.Fatalf("expected 3 stale metrics and an error, got %d, %v", n, err)
+	}
+
+	// and is not retried before the interval is over
+	e.reqClient.BaseUrl = baseUrl
+	if n, err := collect(); err == nil || n != 3 {
+		t.Fatalf("expected 3 stale metrics and the previous error, got %d, %v", n, err)
+	}
+
+	c.lastAttempt = c.lastAttempt.Add(-2 * time.Hour)
+	if n, err := collect(); err != nil || n != 3 {
+		t.Fatalf("expected 3 metrics and no error, got %d, %v", n, err)
+	}
+}
+
+func TestNewHotThreadsCollectorDefaults(t *testing.T) {
+	e := newTestExporter(t, nil)
+	c, _ := NewHotThreadsCollector(e)
+
+	if c.params.Threads != DefaultHotThreadsCount {
+		t.Errorf("expected %d threads, got %d", DefaultHotThreadsCount, c.params.Threads)
+	}
+	if c.interval != DefaultHotThreadsInterval {
+		t.Errorf("expected interval %s, got %s", DefaultHotThreadsInterval, c.interval)
+	}
+}
@@ -6,7 +6,14 @@ package exporter
 
 import (
 	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 func (e *LogstashExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -23,7 +30,118 @@ func (e *LogstashExporter) indexHandler(w http.ResponseWriter, _ *http.Request)
 <body>
 <h1>Logstash Exporter ` + e.buildInfo.Version + `</h1>
 <p><a href='` + e.options.MetricsPath + `'>Metrics</a></p>
+<p><a href='/probe?target=` + url.QueryEscape(e.endpoint) + `'>Probe</a> another Logstash with /probe?target=http://host:9600</p>
+<p><a href='/graph'>Pipeline graphs (DOT)</a>, <a href='/graph?format=mermaid'>Pipeline graphs (Mermaid)</a></p>
 </body>
 </html>
 `))
 }
+
+// metricsHandler serves the metrics of every collector, or only of the collectors named by the collect[]
+// query params, for instance: /metrics?collect[]=node_stats&collect[]=pipelines
+func (e *LogstashExporter) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Query()["collect[]"]) > 0 {
+		e.serveView(w, r)
+		return
+	}
+	e.handler.ServeHTTP(w, r)
+}
+
+// probeHandler serves the metrics of the Logstash given by the target query param, from an exporter built
+// for the request with a client and collectors of its own, for instance: /probe?target=http://ls-12:9600,
+// collect[] works like on the metrics path
+func (e *LogstashExporter) probeHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := parseProbeTarget(r.URL.Query().Get("target"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pe, err := e.newProbeExporter(u)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	pe.metricsHandler(w, r)
+}
+
+// parseProbeTarget parses the target of a probe, http is assumed when the scheme is missing
+func parseProbeTarget(target string) (*url.URL, error) {
+	if target == "" {
+		return nil, errors.New("target parameter is missing")
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.Errorf("invalid target %q", target)
+	}
+	return u, nil
+}
+
+// newProbeExporter returns an exporter of the probed Logstash with the options of e and a registry of its own
+func (e *LogstashExporter) newProbeExporter(u *url.URL) (*LogstashExporter, error) {
+	opts := e.options
+	opts.EndPoint = u.Scheme + "://" + u.Host + strings.TrimSuffix(u.Path, "/")
+	opts.Hostname = u.Hostname()
+	opts.Registry = prometheus.NewRegistry()
+	return NewLogstashExporter(opts)
+}
+
+// serveView serves the collectors named by the collect[] query params from a registry of its own
+func (e *LogstashExporter) serveView(w http.ResponseWriter, r *http.Request) {
+	collectors, err := e.selectCollectors(r.URL.Query()["collect[]"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	registry, err := e.viewRegistry(collectors)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}).ServeHTTP(w, r)
+}
+
+// viewRegistry returns a registry of its own serving the exporter metrics of the given collectors only
+func (e *LogstashExporter) viewRegistry(collectors []Collector) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collectorView{e, collectors}); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// graphHandler renders the pipeline graphs annotated with the vertices stats,
+// query params: pipeline to render a single pipeline, format dot (default) or mermaid
+func (e *LogstashExporter) graphHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "dot"
+	}
+	if format != "dot" && format != "mermaid" {
+		http.Error(w, fmt.Sprintf("unknown format %q, must be dot or mermaid", format), http.StatusBadRequest)
+		return
+	}
+
+	path := "/_node/stats/pipelines"
+	pipeline := r.URL.Query().Get("pipeline")
+	if pipeline != "" {
+		path += "/" + url.PathEscape(pipeline)
+	}
+	info, err := GetLogstashPipelineGraph(e.reqClient, path, e.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		log.Errorf("GetLogstashPipelineGraph <%s> error: <%#v>", path, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	pipelines := e.graphs.annotate(info, time.Now(), pipeline == "")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	switch format {
+	case "mermaid":
+		_, _ = w.Write([]byte(renderMermaid(pipelines)))
+	default:
+		_, _ = w.Write([]byte(renderDOT(pipelines)))
+	}
+}
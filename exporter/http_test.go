@@ -3,3 +3,185 @@
 // Author  : xushiyin
 // contact : yuqingxushiyin@gmail.com
 package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGraphHandler(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats/pipelines/main": "testdata/pipeline_graph.json"})
+
+	tests := []struct {
+		query    string
+		status   int
+		contains []string
+	}{
+		{"?pipeline=main", http.StatusOK, []string{
+			`digraph "main" {`,
+			`"8f1e3a" [shape=diamond, label="if [type] == \"nginx\"\nline 8"];`,
+			`"grok_access" [shape=box, label="filter grok\nid: grok_access\nline 9\nin 1500 / out 1500\n2.000 ms/event\n75.0% of worker time"];`,
+			`"8f1e3a" -> "grok_access" [label="true"];`,
+		}},
+		{"?pipeline=main&format=mermaid", http.StatusOK, []string{
+			"flowchart TB",
+			`p0v2{"if [type] == #quot;nginx#quot;<br/>line 8"}`,
+			`p0v1[("queue")]`,
+			"p0v2 -->|false| p0v4",
+		}},
+		{"?pipeline=main&format=png", http.StatusBadRequest, nil},
+		{"?pipeline=missing", http.StatusBadGateway, nil},
+	}
+	for _, ts := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph"+ts.query, nil))
+		if rec.Code != ts.status {
+			t.Errorf("%s: unexpected status %d", ts.query, rec.Code)
+			continue
+		}
+		body, _ := ioutil.ReadAll(rec.Body)
+		for _, expected := range ts.contains {
+			if !strings.Contains(string(body), expected) {
+				t.Errorf("%s: %q not found in\n%s", ts.query, expected, body)
+			}
+		}
+	}
+}
+
+func TestMetricsHandlerCollect(t *testing.T) {
+	e := newTestExporter(t, map[string]string{
+		"/":               "testdata/root.json",
+		"/_node/stats":    "testdata/node_stats.json",
+		"/_node/os,jvm":   "testdata/node_info.json",
+		"/_node/plugins":  "testdata/node_plugins.json",
+		"/_health_report": "testdata/health_report.json",
+	})
+
+	// the metrics of the collectors left out must be absent
+	metricNames := []string{
+		"logstash_exporter_collector_success",
+		"logstash_os_available_processors",
+		"logstash_node_stats_jvm_threads_count",
+		"logstash_up",
+	}
+	tests := []struct {
+		names    []string
+		expected string
+	}{
+		{[]string{"node_info", "plugins"}, `
+# HELP logstash_exporter_collector_success Whether the collector succeeded during the last scrape
+# TYPE logstash_exporter_collector_success gauge
+logstash_exporter_collector_success{collector="node_info",hostname="test",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="plugins",hostname="test",logstash_usage="logstash"} 1
+# HELP logstash_os_available_processors os_available_processors
+# TYPE logstash_os_available_processors gauge
+logstash_os_available_processors{hostname="test",logstash_usage="logstash"} 8
+# HELP logstash_up Information about the Logstash instance
+# TYPE logstash_up gauge
+logstash_up{hostname="logstash-01",logstash_usage="logstash"} 1
+`},
+		{[]string{"node_stats", "node_stats"}, `
+# HELP logstash_exporter_collector_success Whether the collector succeeded during the last scrape
+# TYPE logstash_exporter_collector_success gauge
+logstash_exporter_collector_success{collector="node_stats",hostname="test",logstash_usage="logstash"} 1
+# HELP logstash_node_stats_jvm_threads_count jvm_threads_count
+# TYPE logstash_node_stats_jvm_threads_count gauge
+logstash_node_stats_jvm_threads_count{hostname="test",logstash_usage="logstash"} 62
+# HELP logstash_up Information about the Logstash instance
+# TYPE logstash_up gauge
+logstash_up{hostname="logstash-01",logstash_usage="logstash"} 1
+`},
+	}
+	for _, ts := range tests {
+		collectors, err := e.selectCollectors(ts.names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry, err := e.viewRegistry(collectors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(ts.expected), metricNames...); err != nil {
+			t.Errorf("%v: %s", ts.names, err)
+		}
+	}
+
+	for query, status := range map[string]int{
+		"?collect[]=node_info&collect[]=plugins": http.StatusOK,
+		"?collect[]=jvm":                         http.StatusBadRequest,
+		"?collect[]=hot_threads":                 http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		e.metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics"+query, nil))
+		if rec.Code != status {
+			t.Errorf("%s: unexpected status %d", query, rec.Code)
+		}
+	}
+}
+
+func TestProbeHandler(t *testing.T) {
+	// the exporter logstash serves nothing, the probed one serves the fixtures
+	e := newTestExporter(t, nil)
+	target := newTestExporter(t, map[string]string{
+		"/":             "testdata/root.json",
+		"/_node/stats":  "testdata/node_stats.json",
+		"/_node/os,jvm": "testdata/node_info.json",
+	}).options.EndPoint
+
+	for query, status := range map[string]int{
+		"?target=" + url.QueryEscape(target):                                    http.StatusOK,
+		"?target=" + url.QueryEscape(strings.TrimPrefix(target, "http://")+"/"): http.StatusOK,
+		"?target=" + url.QueryEscape(target) + "&collect[]=node_info":           http.StatusOK,
+		"?target=" + url.QueryEscape(target) + "&collect[]=jvm":                 http.StatusBadRequest,
+		"":                         http.StatusBadRequest,
+		"?target=ftp://ls-12:9600": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe"+query, nil))
+		if rec.Code != status {
+			t.Errorf("%s: unexpected status %d", query, rec.Code)
+		}
+	}
+
+	// the probe exporter is labelled with the target host
+	u, err := parseProbeTarget(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pe, err := e.newProbeExporter(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP logstash_exporter_collector_success Whether the collector succeeded during the last scrape
+# TYPE logstash_exporter_collector_success gauge
+logstash_exporter_collector_success{collector="flow",hostname="127.0.0.1",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="health_report",hostname="127.0.0.1",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="logging",hostname="127.0.0.1",logstash_usage="logstash"} 0
+logstash_exporter_collector_success{collector="node_info",hostname="127.0.0.1",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="node_stats",hostname="127.0.0.1",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="pipelines",hostname="127.0.0.1",logstash_usage="logstash"} 0
+logstash_exporter_collector_success{collector="plugin_stats",hostname="127.0.0.1",logstash_usage="logstash"} 1
+logstash_exporter_collector_success{collector="plugins",hostname="127.0.0.1",logstash_usage="logstash"} 0
+# HELP logstash_os_available_processors os_available_processors
+# TYPE logstash_os_available_processors gauge
+logstash_os_available_processors{hostname="127.0.0.1",logstash_usage="logstash"} 8
+# HELP logstash_up Information about the Logstash instance
+# TYPE logstash_up gauge
+logstash_up{hostname="logstash-01",logstash_usage="logstash"} 1
+`
+	err = testutil.GatherAndCompare(pe.options.Registry, strings.NewReader(expected),
+		"logstash_exporter_collector_success",
+		"logstash_os_available_processors",
+		"logstash_up",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
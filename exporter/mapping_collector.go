@@ -0,0 +1,217 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mappingRule is a validated MappingRule with its metric description
+type mappingRule struct {
+	MappingRule
+	segments   []string
+	labelNames []string
+	valueType  prometheus.ValueType
+	desc       *prometheus.Desc
+}
+
+// mappingMatch is a value matched by a rule, with the wildcard captures and the element of the last wildcard
+type mappingMatch struct {
+	value    gjson.Result
+	captures []string
+	element  gjson.Result
+}
+
+// MappingCollector exports the metrics declared by the mapping rules of the configuration file
+type MappingCollector struct {
+	export *LogstashExporter
+
+	// rules grouped by api path, so every api is requested once per scrape
+	rules map[string][]*mappingRule
+}
+
+func init() {
+	registerCollector("mapping", true, func(e *LogstashExporter) (Collector, error) {
+		// without mapping rules there is nothing to collect
+		if len(e.options.MappingRules) == 0 {
+			return nil, nil
+		}
+		return NewMappingCollector(e)
+	})
+}
+
+func NewMappingCollector(e *LogstashExporter) (*MappingCollector, error) {
+	c := &MappingCollector{
+		export: e,
+		rules:  make(map[string][]*mappingRule),
+	}
+	for _, rule := range e.options.MappingRules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		r := &mappingRule{
+			MappingRule: rule,
+			segments:    splitJSONPath(rule.JSONPath),
+			valueType:   prometheus.GaugeValue,
+		}
+		if rule.Type == "counter" {
+			r.valueType = prometheus.CounterValue
+		}
+		for name := range rule.Labels {
+			r.labelNames = append(r.labelNames, name)
+		}
+		sort.Strings(r.labelNames)
+		r.desc = prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", rule.Name),
+			rule.Help,
+			r.labelNames,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		)
+		c.rules[rule.APIPath] = append(c.rules[rule.APIPath], r)
+	}
+	return c, nil
+}
+
+func (c *MappingCollector) Name() string {
+	return "mapping"
+}
+
+func (c *MappingCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, rules := range c.rules {
+		for _, rule := range rules {
+			ch <- rule.desc
+		}
+	}
+}
+
+func (c *MappingCollector) Collect(ch chan<- prometheus.Metric) error {
+	// a failing api does not prevent the rules of the other apis from being collected
+	var lastErr error
+	for apiPath, rules := range c.rules {
+		var body []byte
+		var err error
+		if apiPath == NodeStatsPath {
+			// shared with the node_stats, flow and plugin_stats collectors
+			body, err = c.export.nodeStatsBody()
+		} else {
+			body, err = GetLogstashRaw(c.export.reqClient, apiPath, c.export.options.ScrapeTimeoutMillisecond)
+		}
+		if err != nil {
+			lastErr = errors.Wrap(err, fmt.Sprintf("GetLogstashRaw <%s>", apiPath))
+			continue
+		}
+		root := gjson.ParseBytes(body)
+		for _, rule := range rules {
+			c.collectRule(ch, rule, root)
+		}
+	}
+	return lastErr
+}
+
+func (c *MappingCollector) collectRule(ch chan<- prometheus.Metric, rule *mappingRule, root gjson.Result) {
+	seen := make(map[string]bool)
+	for _, match := range matchJSONPath(root, rule.segments) {
+		var value float64
+		switch match.value.Type {
+		case gjson.Number:
+			value = match.value.Float()
+		case gjson.True, gjson.False:
+			if match.value.Bool() {
+				value = 1
+			}
+		default:
+			log.Debugf("skip non numeric value <%s> of mapping %s", match.value.Raw, rule.Name)
+			continue
+		}
+
+		labelValues := make([]string, 0, len(rule.labelNames))
+		for _, name := range rule.labelNames {
+			labelValues = append(labelValues, match.label(rule.Labels[name]))
+		}
+		// labels which do not identify the matched values would produce duplicated series
+		id := strings.Join(labelValues, "\xff")
+		if seen[id] {
+			log.Debugf("skip duplicated labels <%v> of mapping %s", labelValues, rule.Name)
+			continue
+		}
+		seen[id] = true
+
+		ch <- prometheus.MustNewConstMetric(
+			rule.desc,
+			rule.valueType,
+			value*rule.Scale,
+			labelValues...,
+		)
+	}
+}
+
+// label resolves a label reference, $N is the Nth wildcard capture, anything else a gjson path relative to the element
+func (m mappingMatch) label(ref string) string {
+	if strings.HasPrefix(ref, "$") {
+		if idx, err := strconv.Atoi(ref[1:]); err == nil {
+			if idx >= 1 && idx <= len(m.captures) {
+				return m.captures[idx-1]
+			}
+			return ""
+		}
+	}
+	return m.element.Get(ref).String()
+}
+
+// matchJSONPath walks segments from root, expanding the `*` and `#` wildcards
+func matchJSONPath(root gjson.Result, segments []string) []mappingMatch {
+	matches := []mappingMatch{{value: root, element: root}}
+	for _, segment := range segments {
+		var next []mappingMatch
+		for _, m := range matches {
+			switch {
+			case segment == "*" && m.value.IsObject(), segment == "#" && m.value.IsArray():
+				idx := 0
+				m.value.ForEach(func(key, child gjson.Result) bool {
+					capture := key.String()
+					if m.value.IsArray() {
+						capture = strconv.Itoa(idx)
+						idx++
+					}
+					next = append(next, mappingMatch{
+						value:    child,
+						captures: append(append([]string{}, m.captures...), capture),
+						element:  child,
+					})
+					return true
+				})
+			case segment != "*" && segment != "#":
+				if child := m.value.Get(segment); child.Exists() {
+					next = append(next, mappingMatch{value: child, captures: m.captures, element: m.element})
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// splitJSONPath splits a gjson path on the dots which are not escaped
+func splitJSONPath(path string) []string {
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			current.WriteByte(path[i])
+			current.WriteByte(path[i+1])
+			i++
+		case path[i] == '.':
+			segments = append(segments, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(path[i])
+		}
+	}
+	return append(segments, current.String())
+}
@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestMappingCollector(t *testing.T) {
+	cfg, err := LoadConfig("testdata/config.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	e.options.MappingRules = cfg.Mappings
+	c, err := NewMappingCollector(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP logstash_filter_duration_seconds_total filter_duration_seconds_total mapped from /_node/stats pipelines.*.plugins.filters.#.events.duration_in_millis
+# TYPE logstash_filter_duration_seconds_total counter
+logstash_filter_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access"} 4.2
+# HELP logstash_heap_used_ratio heap_used_ratio mapped from /_node/stats jvm.mem.heap_used_percent
+# TYPE logstash_heap_used_ratio gauge
+logstash_heap_used_ratio{hostname="test",logstash_usage="logstash"} 0.21
+`
+	err = testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMappingRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule  MappingRule
+		valid bool
+	}{
+		{MappingRule{JSONPath: "jvm.uptime_in_millis", Name: "jvm_uptime_seconds"}, true},
+		{MappingRule{JSONPath: "jvm.uptime_in_millis", Name: "jvm-uptime"}, false},
+		{MappingRule{Name: "jvm_uptime_seconds"}, false},
+		{MappingRule{JSONPath: "jvm.uptime_in_millis", Name: "jvm_uptime_seconds", Type: "histogram"}, false},
+		{MappingRule{JSONPath: "jvm.uptime_in_millis", Name: "jvm_uptime_seconds", APIPath: "_node/stats"}, false},
+		{MappingRule{JSONPath: "jvm.uptime_in_millis", Name: "jvm_uptime_seconds", Labels: map[string]string{"hostname": "host"}}, false},
+	}
+	for _, ts := range tests {
+		err := ts.rule.validate()
+		if (err == nil) != ts.valid {
+			t.Errorf("rule %#v: unexpected validation result <%v>", ts.rule, err)
+		}
+	}
+}
+
+func TestLoadConfigCollectors(t *testing.T) {
+	cfg, err := LoadConfig("testdata/config.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Collectors) != 2 || !cfg.Collectors["hot_threads"] || cfg.Collectors["plugin_stats"] {
+		t.Errorf("unexpected collectors %v", cfg.Collectors)
+	}
+}
@@ -0,0 +1,146 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NodeInfoCollector exports the jvm and os info of the node from /_node/os,jvm, the values only change on restart
+type NodeInfoCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	JvmInfo               *prometheus.Desc
+	JvmGCCollectorInfo    *prometheus.Desc
+	JvmStartTime          *prometheus.Desc
+	JvmHeapInitBytes      *prometheus.Desc
+	JvmHeapMaxBytes       *prometheus.Desc
+	OsInfo                *prometheus.Desc
+	OsAvailableProcessors *prometheus.Desc
+}
+
+func init() {
+	registerCollector("node_info", true, func(e *LogstashExporter) (Collector, error) {
+		return NewNodeInfoCollector(e)
+	})
+}
+
+func NewNodeInfoCollector(e *LogstashExporter) (*NodeInfoCollector, error) {
+	return &NodeInfoCollector{
+		export:  e,
+		ReqPath: "/_node/os,jvm",
+
+		JvmInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "jvm_info"),
+			"jvm_info",
+			[]string{"vendor", "version", "vm_name", "vm_version"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		JvmGCCollectorInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "jvm_gc_collector_info"),
+			"jvm_gc_collector_info",
+			[]string{"collector"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		JvmStartTime: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "jvm_start_time_seconds"),
+			"jvm_start_time_seconds",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		JvmHeapInitBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "jvm_heap_init_bytes"),
+			"jvm_heap_init_bytes",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		JvmHeapMaxBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "jvm_heap_max_bytes"),
+			"jvm_heap_max_bytes",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OsInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "os_info"),
+			"os_info",
+			[]string{"name", "arch", "version"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OsAvailableProcessors: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "os_available_processors"),
+			"os_available_processors",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *NodeInfoCollector) Name() string {
+	return "node_info"
+}
+
+func (c *NodeInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *NodeInfoCollector) Collect(ch chan<- prometheus.Metric) error {
+	info, err := GetLogstashNodeInfo(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodeInfo <%s>", c.ReqPath))
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmInfo,
+		prometheus.GaugeValue,
+		float64(1),
+		info.JVM.VMVendor, info.JVM.Version, info.JVM.VMName, info.JVM.VMVersion,
+	)
+
+	for _, collector := range info.JVM.GCCollectors {
+		ch <- prometheus.MustNewConstMetric(
+			c.JvmGCCollectorInfo,
+			prometheus.GaugeValue,
+			float64(1),
+			collector,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmStartTime,
+		prometheus.GaugeValue,
+		float64(info.JVM.StartTimeInMillis)/1000,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmHeapInitBytes,
+		prometheus.GaugeValue,
+		float64(info.JVM.Mem.HeapInitInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmHeapMaxBytes,
+		prometheus.GaugeValue,
+		float64(info.JVM.Mem.HeapMaxInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.OsInfo,
+		prometheus.GaugeValue,
+		float64(1),
+		info.OS.Name, info.OS.Arch, info.OS.Version,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.OsAvailableProcessors,
+		prometheus.GaugeValue,
+		float64(info.OS.AvailableProcessors),
+	)
+	return nil
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestNodeInfoCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/os,jvm": "testdata/node_info.json"})
+	c, _ := NewNodeInfoCollector(e)
+
+	expected := `
+# HELP logstash_jvm_gc_collector_info jvm_gc_collector_info
+# TYPE logstash_jvm_gc_collector_info gauge
+logstash_jvm_gc_collector_info{collector="G1 Concurrent GC",hostname="test",logstash_usage="logstash"} 1
+logstash_jvm_gc_collector_info{collector="G1 Old Generation",hostname="test",logstash_usage="logstash"} 1
+logstash_jvm_gc_collector_info{collector="G1 Young Generation",hostname="test",logstash_usage="logstash"} 1
+# HELP logstash_jvm_heap_init_bytes jvm_heap_init_bytes
+# TYPE logstash_jvm_heap_init_bytes gauge
+logstash_jvm_heap_init_bytes{hostname="test",logstash_usage="logstash"} 1.073741824e+09
+# HELP logstash_jvm_heap_max_bytes jvm_heap_max_bytes
+# TYPE logstash_jvm_heap_max_bytes gauge
+logstash_jvm_heap_max_bytes{hostname="test",logstash_usage="logstash"} 1.073741824e+09
+# HELP logstash_jvm_info jvm_info
+# TYPE logstash_jvm_info gauge
+logstash_jvm_info{hostname="test",logstash_usage="logstash",vendor="Eclipse Adoptium",version="21.0.4",vm_name="OpenJDK 64-Bit Server VM",vm_version="21.0.4"} 1
+# HELP logstash_jvm_start_time_seconds jvm_start_time_seconds
+# TYPE logstash_jvm_start_time_seconds gauge
+logstash_jvm_start_time_seconds{hostname="test",logstash_usage="logstash"} 1.729238400123e+09
+# HELP logstash_os_available_processors os_available_processors
+# TYPE logstash_os_available_processors gauge
+logstash_os_available_processors{hostname="test",logstash_usage="logstash"} 8
+# HELP logstash_os_info os_info
+# TYPE logstash_os_info gauge
+logstash_os_info{arch="amd64",hostname="test",logstash_usage="logstash",name="Linux",version="5.15.0-122-generic"} 1
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
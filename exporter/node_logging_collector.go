@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"strings"
+)
+
+// loggerLevelValues maps the log4j levels to their log4j int levels, higher is more verbose, ALL gets 700 instead of MaxInt32
+var loggerLevelValues = map[string]float64{
+	"OFF":   0,
+	"FATAL": 100,
+	"ERROR": 200,
+	"WARN":  300,
+	"INFO":  400,
+	"DEBUG": 500,
+	"TRACE": 600,
+	"ALL":   700,
+}
+
+// NodeLoggingCollector exports the level of every logger from /_node/logging, levels are changed at runtime
+// with PUT /_node/logging and only reset on restart
+type NodeLoggingCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	LoggerLevel *prometheus.Desc
+}
+
+func init() {
+	registerCollector("logging", true, func(e *LogstashExporter) (Collector, error) {
+		return NewNodeLoggingCollector(e)
+	})
+}
+
+func NewNodeLoggingCollector(e *LogstashExporter) (*NodeLoggingCollector, error) {
+	return &NodeLoggingCollector{
+		export:  e,
+		ReqPath: "/_node/logging",
+
+		LoggerLevel: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "logger_level"),
+			"logger_level",
+			[]string{"logger"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *NodeLoggingCollector) Name() string {
+	return "logging"
+}
+
+func (c *NodeLoggingCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *NodeLoggingCollector) Collect(ch chan<- prometheus.Metric) error {
+	info, err := GetLogstashNodeLogging(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodeLogging <%s>", c.ReqPath))
+	}
+
+	for logger, level := range info.Loggers {
+		value, ok := loggerLevelValues[strings.ToUpper(level)]
+		if !ok {
+			log.Debugf("skip unknown level <%s> of logger %s", level, logger)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.LoggerLevel,
+			prometheus.GaugeValue,
+			value,
+			logger,
+		)
+	}
+	return nil
+}
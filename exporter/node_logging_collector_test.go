@@ -0,0 +1,25 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestNodeLoggingCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/logging": "testdata/node_logging.json"})
+	c, _ := NewNodeLoggingCollector(e)
+
+	expected := `
+# HELP logstash_logger_level logger_level
+# TYPE logstash_logger_level gauge
+logstash_logger_level{hostname="test",logger="logstash.agent",logstash_usage="logstash"} 400
+logstash_logger_level{hostname="test",logger="logstash.filters.grok",logstash_usage="logstash"} 300
+logstash_logger_level{hostname="test",logger="logstash.outputs.elasticsearch",logstash_usage="logstash"} 500
+logstash_logger_level{hostname="test",logger="org.logstash.execution.WorkerLoop",logstash_usage="logstash"} 600
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,144 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NodePipelinesCollector exports the worker and batch settings of every pipeline from /_node/pipelines
+type NodePipelinesCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	PipelineInfo                   *prometheus.Desc
+	PipelineWorkers                *prometheus.Desc
+	PipelineBatchSize              *prometheus.Desc
+	PipelineBatchDelay             *prometheus.Desc
+	PipelineConfigReloadAutomatic  *prometheus.Desc
+	PipelineDeadLetterQueueEnabled *prometheus.Desc
+}
+
+func init() {
+	registerCollector("pipelines", true, func(e *LogstashExporter) (Collector, error) {
+		return NewNodePipelinesCollector(e)
+	})
+}
+
+func NewNodePipelinesCollector(e *LogstashExporter) (*NodePipelinesCollector, error) {
+	const subsystem = "pipeline"
+	return &NodePipelinesCollector{
+		export:  e,
+		ReqPath: "/_node/pipelines",
+
+		PipelineInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "info"),
+			"info",
+			[]string{"pipeline", "ephemeral_id", "hash"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineWorkers: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "workers"),
+			"workers",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineBatchSize: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "batch_size"),
+			"batch_size",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineBatchDelay: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "batch_delay_seconds"),
+			"batch_delay_seconds",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineConfigReloadAutomatic: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "config_reload_automatic"),
+			"config_reload_automatic",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		PipelineDeadLetterQueueEnabled: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "dead_letter_queue_enabled"),
+			"dead_letter_queue_enabled",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *NodePipelinesCollector) Name() string {
+	return "pipelines"
+}
+
+func (c *NodePipelinesCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *NodePipelinesCollector) Collect(ch chan<- prometheus.Metric) error {
+	info, err := GetLogstashNodePipelines(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodePipelines <%s>", c.ReqPath))
+	}
+
+	for pipelineID, pipeline := range info.Pipelines {
+		// the hash changes with every config rollout
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineInfo,
+			prometheus.GaugeValue,
+			float64(1),
+			pipelineID, pipeline.EphemeralID, pipeline.Hash,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineWorkers,
+			prometheus.GaugeValue,
+			float64(pipeline.Workers),
+			pipelineID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineBatchSize,
+			prometheus.GaugeValue,
+			float64(pipeline.BatchSize),
+			pipelineID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineBatchDelay,
+			prometheus.GaugeValue,
+			float64(pipeline.BatchDelay)/1000,
+			pipelineID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineConfigReloadAutomatic,
+			prometheus.GaugeValue,
+			boolToFloat64(pipeline.ConfigReloadAutomatic),
+			pipelineID,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PipelineDeadLetterQueueEnabled,
+			prometheus.GaugeValue,
+			boolToFloat64(pipeline.DeadLetterQueueEnabled),
+			pipelineID,
+		)
+	}
+	return nil
+}
+
+func boolToFloat64(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
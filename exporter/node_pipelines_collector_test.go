@@ -0,0 +1,37 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestNodePipelinesCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/pipelines": "testdata/node_pipelines.json"})
+	c, _ := NewNodePipelinesCollector(e)
+
+	expected := `
+# HELP logstash_pipeline_batch_delay_seconds batch_delay_seconds
+# TYPE logstash_pipeline_batch_delay_seconds gauge
+logstash_pipeline_batch_delay_seconds{hostname="test",logstash_usage="logstash",pipeline="main"} 0.05
+# HELP logstash_pipeline_batch_size batch_size
+# TYPE logstash_pipeline_batch_size gauge
+logstash_pipeline_batch_size{hostname="test",logstash_usage="logstash",pipeline="main"} 125
+# HELP logstash_pipeline_config_reload_automatic config_reload_automatic
+# TYPE logstash_pipeline_config_reload_automatic gauge
+logstash_pipeline_config_reload_automatic{hostname="test",logstash_usage="logstash",pipeline="main"} 1
+# HELP logstash_pipeline_dead_letter_queue_enabled dead_letter_queue_enabled
+# TYPE logstash_pipeline_dead_letter_queue_enabled gauge
+logstash_pipeline_dead_letter_queue_enabled{hostname="test",logstash_usage="logstash",pipeline="main"} 0
+# HELP logstash_pipeline_info info
+# TYPE logstash_pipeline_info gauge
+logstash_pipeline_info{ephemeral_id="0b4a36a6-8e49-4b5c-8a4e-3f1f5a1ab111",hash="f6c1d5e3b3a2e0c9d8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5",hostname="test",logstash_usage="logstash",pipeline="main"} 1
+# HELP logstash_pipeline_workers workers
+# TYPE logstash_pipeline_workers gauge
+logstash_pipeline_workers{hostname="test",logstash_usage="logstash",pipeline="main"} 8
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
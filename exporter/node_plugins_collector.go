@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"strings"
+)
+
+// NodePluginsCollector exports the inventory of installed plugins from /_node/plugins
+type NodePluginsCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	PluginsInstalledInfo *prometheus.Desc
+}
+
+func init() {
+	registerCollector("plugins", true, func(e *LogstashExporter) (Collector, error) {
+		return NewNodePluginsCollector(e)
+	})
+}
+
+func NewNodePluginsCollector(e *LogstashExporter) (*NodePluginsCollector, error) {
+	return &NodePluginsCollector{
+		export:  e,
+		ReqPath: "/_node/plugins",
+
+		PluginsInstalledInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, "", "plugins_installed_info"),
+			"plugins_installed_info",
+			[]string{"name", "version", "plugin_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *NodePluginsCollector) Name() string {
+	return "plugins"
+}
+
+func (c *NodePluginsCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *NodePluginsCollector) Collect(ch chan<- prometheus.Metric) error {
+	info, err := GetLogstashNodePlugins(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodePlugins <%s>", c.ReqPath))
+	}
+
+	for _, plugin := range info.Plugins {
+		ch <- prometheus.MustNewConstMetric(
+			c.PluginsInstalledInfo,
+			prometheus.GaugeValue,
+			float64(1),
+			plugin.Name, plugin.Version, pluginType(plugin.Name),
+		)
+	}
+	return nil
+}
+
+// pluginType extracts the type from a plugin gem name, such as filter for logstash-filter-grok
+func pluginType(name string) string {
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) < 3 || parts[0] != "logstash" {
+		return "other"
+	}
+	return parts[1]
+}
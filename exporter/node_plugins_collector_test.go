@@ -0,0 +1,24 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestNodePluginsCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/plugins": "testdata/node_plugins.json"})
+	c, _ := NewNodePluginsCollector(e)
+
+	expected := `
+# HELP logstash_plugins_installed_info plugins_installed_info
+# TYPE logstash_plugins_installed_info gauge
+logstash_plugins_installed_info{hostname="test",logstash_usage="logstash",name="logstash-codec-json",plugin_type="codec",version="3.1.0"} 1
+logstash_plugins_installed_info{hostname="test",logstash_usage="logstash",name="logstash-filter-grok",plugin_type="filter",version="4.4.2"} 1
+logstash_plugins_installed_info{hostname="test",logstash_usage="logstash",name="logstash-integration-kafka",plugin_type="integration",version="10.9.0"} 1
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -5,10 +5,17 @@
 package exporter
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
 )
 
+// maxErrorMessageLength bounds the size of error messages exported as label values
+const maxErrorMessageLength = 256
+
 // NodeStatsCollector type
 type NodeStatsCollector struct {
 	export  *LogstashExporter
@@ -41,18 +48,69 @@ type NodeStatsCollector struct {
 	ProcessMemTotalVirtualInBytes  *prometheus.Desc
 	ProcessCPUTotalInMillis        *prometheus.Desc
 	ProcessCPUPercent              *prometheus.Desc
+	ProcessCPULoadAverage          *prometheus.Desc
+
+	OSCgroupCpuacctUsage         *prometheus.Desc
+	OSCgroupCPUCfsPeriod         *prometheus.Desc
+	OSCgroupCPUCfsQuota          *prometheus.Desc
+	OSCgroupCPUElapsedPeriods    *prometheus.Desc
+	OSCgroupCPUThrottledPeriods  *prometheus.Desc
+	OSCgroupCPUThrottledDuration *prometheus.Desc
+
+	GeoipDatabaseStatusInfo           *prometheus.Desc
+	GeoipDatabaseLastUpdatedTimestamp *prometheus.Desc
+	GeoipDatabaseFailCheckIn          *prometheus.Desc
+	GeoipDownloadSuccesses            *prometheus.Desc
+	GeoipDownloadFailures             *prometheus.Desc
+	GeoipDownloadLastCheckedTimestamp *prometheus.Desc
+	GeoipDownloadStatusInfo           *prometheus.Desc
+
+	EventsIn                *prometheus.Desc
+	EventsFiltered          *prometheus.Desc
+	EventsOut               *prometheus.Desc
+	EventsDuration          *prometheus.Desc
+	EventsQueuePushDuration *prometheus.Desc
+
+	ReloadsSuccesses *prometheus.Desc
+	ReloadsFailures  *prometheus.Desc
+
+	PipelineDuration          *prometheus.Desc
+	PipelineEventsIn          *prometheus.Desc
+	PipelineEventsFiltered    *prometheus.Desc
+	PipelineEventsOut         *prometheus.Desc
+	PipelineQueuePushDuration *prometheus.Desc
+
+	PipelineReloadsSuccesses            *prometheus.Desc
+	PipelineReloadsFailures             *prometheus.Desc
+	PipelineReloadsLastSuccessTimestamp *prometheus.Desc
+	PipelineReloadsLastFailureTimestamp *prometheus.Desc
+	PipelineReloadsLastErrorInfo        *prometheus.Desc
+
+	PipelineQueueEvents           *prometheus.Desc
+	PipelineQueueSizeInBytes      *prometheus.Desc
+	PipelineQueueMaxSizeInBytes   *prometheus.Desc
+	PipelineQueuePageCapacity     *prometheus.Desc
+	PipelineQueueMaxUnreadEvents  *prometheus.Desc
+	PipelineQueueFreeSpaceInBytes *prometheus.Desc
+
+	PipelineDeadLetterQueueSizeInBytes    *prometheus.Desc
+	PipelineDeadLetterQueueMaxSizeInBytes *prometheus.Desc
+	PipelineDeadLetterQueueDroppedEvents  *prometheus.Desc
+	PipelineDeadLetterQueueExpiredEvents  *prometheus.Desc
+	PipelineDeadLetterQueueInfo           *prometheus.Desc
+}
 
-	PipelineDuration       *prometheus.Desc
-	PipelineEventsIn       *prometheus.Desc
-	PipelineEventsFiltered *prometheus.Desc
-	PipelineEventsOut      *prometheus.Desc
+func init() {
+	registerCollector("node_stats", true, func(e *LogstashExporter) (Collector, error) {
+		return NewNodeStatsCollector(e)
+	})
 }
 
 func NewNodeStatsCollector(e *LogstashExporter) (*NodeStatsCollector, error) {
 	const subsystem = "node_stats"
 	return &NodeStatsCollector{
 		export:  e,
-		ReqPath: "/_node/stats",
+		ReqPath: NodeStatsPath,
 
 		LogstashInfo: prometheus.NewDesc(
 			prometheus.BuildFQName(e.namespace, "", "instance_info"),
@@ -208,6 +266,153 @@ func NewNodeStatsCollector(e *LogstashExporter) (*NodeStatsCollector, error) {
 			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
 		),
 
+		ProcessCPULoadAverage: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "process_cpu_load_average"),
+			"process_cpu_load_average",
+			[]string{"interval"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCpuacctUsage: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpuacct_usage_seconds_total"),
+			"os_cgroup_cpuacct_usage_seconds_total",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCPUCfsPeriod: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpu_cfs_period_seconds"),
+			"os_cgroup_cpu_cfs_period_seconds",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCPUCfsQuota: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpu_cfs_quota_seconds"),
+			"os_cgroup_cpu_cfs_quota_seconds",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCPUElapsedPeriods: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpu_elapsed_periods_total"),
+			"os_cgroup_cpu_elapsed_periods_total",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCPUThrottledPeriods: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpu_throttled_periods_total"),
+			"os_cgroup_cpu_throttled_periods_total",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OSCgroupCPUThrottledDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "os_cgroup_cpu_throttled_seconds_total"),
+			"os_cgroup_cpu_throttled_seconds_total",
+			[]string{"control_group"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDatabaseStatusInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_database_status_info"),
+			"geoip_database_status_info",
+			[]string{"database", "status"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDatabaseLastUpdatedTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_database_last_updated_timestamp_seconds"),
+			"geoip_database_last_updated_timestamp_seconds",
+			[]string{"database"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDatabaseFailCheckIn: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_database_fail_check_in_seconds"),
+			"geoip_database_fail_check_in_seconds",
+			[]string{"database"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDownloadSuccesses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_download_successes_total"),
+			"geoip_download_successes_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDownloadFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_download_failures_total"),
+			"geoip_download_failures_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDownloadLastCheckedTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_download_last_checked_timestamp_seconds"),
+			"geoip_download_last_checked_timestamp_seconds",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		GeoipDownloadStatusInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "geoip_download_status_info"),
+			"geoip_download_status_info",
+			[]string{"status"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsIn: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_in_total"),
+			"events_in_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsFiltered: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_filtered_total"),
+			"events_filtered_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsOut: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_out_total"),
+			"events_out_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_duration_seconds_total"),
+			"events_duration_seconds_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsQueuePushDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_queue_push_duration_seconds_total"),
+			"events_queue_push_duration_seconds_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		ReloadsSuccesses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "reloads_successes_total"),
+			"reloads_successes_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		ReloadsFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "reloads_failures_total"),
+			"reloads_failures_total",
+			nil,
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
 		PipelineDuration: prometheus.NewDesc(
 			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_duration_seconds_total"),
 			"pipeline_duration_seconds_total",
@@ -235,274 +440,742 @@ func NewNodeStatsCollector(e *LogstashExporter) (*NodeStatsCollector, error) {
 			[]string{"pipeline"},
 			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
 		),
-	}, nil
-}
 
-func (c *NodeStatsCollector) Collect(ch chan<- prometheus.Metric) {
-	stats, err := GetLogstashNodeStats(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
-	if err != nil {
-		log.Fatalf("GetLogstashNodeStats <%s> error: <%#v>", c.ReqPath, err)
-	} else {
+		PipelineQueuePushDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_push_duration_seconds_total"),
+			"pipeline_queue_push_duration_seconds_total",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.LogstashInfo,
-			prometheus.GaugeValue,
-			float64(1),
-			stats.Version, stats.HTTPAddress)
+		PipelineReloadsSuccesses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_reloads_successes_total"),
+			"pipeline_reloads_successes_total",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.JvmThreadsCount,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Threads.Count),
-		)
+		PipelineReloadsFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_reloads_failures_total"),
+			"pipeline_reloads_failures_total",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.JvmThreadsPeakCount,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Threads.PeakCount),
-		)
+		PipelineReloadsLastSuccessTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_reloads_last_success_timestamp_seconds"),
+			"pipeline_reloads_last_success_timestamp_seconds",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemHeapUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.HeapUsedInBytes),
-		)
+		PipelineReloadsLastFailureTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_reloads_last_failure_timestamp_seconds"),
+			"pipeline_reloads_last_failure_timestamp_seconds",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemHeapUsedPercent,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.HeapUsedPercent),
-		)
+		PipelineReloadsLastErrorInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_reloads_last_error_info"),
+			"pipeline_reloads_last_error_info",
+			[]string{"pipeline", "message"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemHeapMaxInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.HeapMaxInBytes),
-		)
+		PipelineQueueEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_events"),
+			"pipeline_queue_events",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemHeapCommittedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.HeapCommittedInBytes),
-		)
+		PipelineQueueSizeInBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_size_bytes"),
+			"pipeline_queue_size_bytes",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemNonHeapUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.NonHeapUsedInBytes),
-		)
+		PipelineQueueMaxSizeInBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_max_size_bytes"),
+			"pipeline_queue_max_size_bytes",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemNonHeapCommittedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.NonHeapCommittedInBytes),
-		)
+		PipelineQueuePageCapacity: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_page_capacity_bytes"),
+			"pipeline_queue_page_capacity_bytes",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
-			"old",
-		)
+		PipelineQueueMaxUnreadEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_max_unread_events"),
+			"pipeline_queue_max_unread_events",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.UsedInBytes),
-			"old",
-		)
+		PipelineQueueFreeSpaceInBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_queue_free_space_bytes"),
+			"pipeline_queue_free_space_bytes",
+			[]string{"pipeline", "queue_type"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakMaxInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
-			"old",
-		)
+		PipelineDeadLetterQueueSizeInBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_dead_letter_queue_size_bytes"),
+			"pipeline_dead_letter_queue_size_bytes",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolMaxInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.MaxInBytes),
-			"old",
-		)
+		PipelineDeadLetterQueueMaxSizeInBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_dead_letter_queue_max_size_bytes"),
+			"pipeline_dead_letter_queue_max_size_bytes",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolCommittedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.CommittedInBytes),
-			"old",
-		)
+		PipelineDeadLetterQueueDroppedEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_dead_letter_queue_dropped_events_total"),
+			"pipeline_dead_letter_queue_dropped_events_total",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
-			"young",
-		)
+		PipelineDeadLetterQueueExpiredEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_dead_letter_queue_expired_events_total"),
+			"pipeline_dead_letter_queue_expired_events_total",
+			[]string{"pipeline"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
 
-		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Young.UsedInBytes),
-			"young",
-		)
+		PipelineDeadLetterQueueInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "pipeline_dead_letter_queue_info"),
+			"pipeline_dead_letter_queue_info",
+			[]string{"pipeline", "storage_policy", "last_error"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *NodeStatsCollector) Name() string {
+	return "node_stats"
+}
+
+func (c *NodeStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
 
+func (c *NodeStatsCollector) Collect(ch chan<- prometheus.Metric) error {
+	stats, err := c.export.nodeStats()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodeStats <%s>", c.ReqPath))
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.LogstashInfo,
+		prometheus.GaugeValue,
+		float64(1),
+		stats.Version, stats.HTTPAddress)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmThreadsCount,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Threads.Count),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.JvmThreadsPeakCount,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Threads.PeakCount),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemHeapUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.HeapUsedInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemHeapUsedPercent,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.HeapUsedPercent),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemHeapMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.HeapMaxInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemHeapCommittedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.HeapCommittedInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemNonHeapUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.NonHeapUsedInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemNonHeapCommittedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.NonHeapCommittedInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.UsedInBytes),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.MaxInBytes),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolCommittedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.CommittedInBytes),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Young.UsedInBytes),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Young.MaxInBytes),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolCommittedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Young.CommittedInBytes),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
+		"survivor",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolUsedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Survivor.UsedInBytes),
+		"survivor",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolPeakMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
+		"survivor",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolMaxInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Survivor.MaxInBytes),
+		"survivor",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.MemPoolCommittedInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Mem.Pools.Survivor.CommittedInBytes),
+		"survivor",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.GCCollectionTimeInMillis,
+		prometheus.CounterValue,
+		float64(stats.Jvm.Gc.Collectors.Old.CollectionTimeInMillis),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.GCCollectionCount,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Gc.Collectors.Old.CollectionCount),
+		"old",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.GCCollectionTimeInMillis,
+		prometheus.CounterValue,
+		float64(stats.Jvm.Gc.Collectors.Young.CollectionTimeInMillis),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.GCCollectionCount,
+		prometheus.GaugeValue,
+		float64(stats.Jvm.Gc.Collectors.Young.CollectionCount),
+		"young",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessOpenFileDescriptors,
+		prometheus.GaugeValue,
+		float64(stats.Process.OpenFileDescriptors),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessPeakOpenFileDescriptors,
+		prometheus.GaugeValue,
+		float64(stats.Process.PeakOpenFileDescriptors),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessMaxFileDescriptors,
+		prometheus.GaugeValue,
+		float64(stats.Process.MaxFileDescriptors),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessMemTotalVirtualInBytes,
+		prometheus.GaugeValue,
+		float64(stats.Process.Mem.TotalVirtualInBytes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessCPUTotalInMillis,
+		prometheus.CounterValue,
+		float64(stats.Process.CPU.TotalInMillis/1000),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessCPUPercent,
+		prometheus.GaugeValue,
+		float64(stats.Process.CPU.Percent),
+	)
+
+	if loadAverage := stats.Process.CPU.LoadAverage; loadAverage != nil {
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakMaxInBytes,
+			c.ProcessCPULoadAverage,
 			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
-			"young",
+			loadAverage.OneMinute,
+			"1m",
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolMaxInBytes,
+			c.ProcessCPULoadAverage,
 			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Young.MaxInBytes),
-			"young",
+			loadAverage.FiveMinutes,
+			"5m",
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolCommittedInBytes,
+			c.ProcessCPULoadAverage,
 			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Young.CommittedInBytes),
-			"young",
+			loadAverage.FifteenMinutes,
+			"15m",
 		)
+	}
 
+	if cgroup := stats.OS.Cgroup; cgroup != nil {
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakUsedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakUsedInBytes),
-			"survivor",
+			c.OSCgroupCpuacctUsage,
+			prometheus.CounterValue,
+			float64(cgroup.Cpuacct.UsageNanos)/1e9,
+			cgroup.Cpuacct.ControlGroup,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolUsedInBytes,
+			c.OSCgroupCPUCfsPeriod,
 			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Survivor.UsedInBytes),
-			"survivor",
+			float64(cgroup.CPU.CfsPeriodMicros)/1e6,
+			cgroup.CPU.ControlGroup,
 		)
 
+		// -1 means the cpu quota is unlimited
+		cfsQuota := float64(-1)
+		if cgroup.CPU.CfsQuotaMicros >= 0 {
+			cfsQuota = float64(cgroup.CPU.CfsQuotaMicros) / 1e6
+		}
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolPeakMaxInBytes,
+			c.OSCgroupCPUCfsQuota,
 			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Old.PeakMaxInBytes),
-			"survivor",
+			cfsQuota,
+			cgroup.CPU.ControlGroup,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolMaxInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Survivor.MaxInBytes),
-			"survivor",
+			c.OSCgroupCPUElapsedPeriods,
+			prometheus.CounterValue,
+			float64(cgroup.CPU.Stat.NumberOfElapsedPeriods),
+			cgroup.CPU.ControlGroup,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.MemPoolCommittedInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Mem.Pools.Survivor.CommittedInBytes),
-			"survivor",
+			c.OSCgroupCPUThrottledPeriods,
+			prometheus.CounterValue,
+			float64(cgroup.CPU.Stat.NumberOfTimesThrottled),
+			cgroup.CPU.ControlGroup,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.GCCollectionTimeInMillis,
+			c.OSCgroupCPUThrottledDuration,
 			prometheus.CounterValue,
-			float64(stats.Jvm.Gc.Collectors.Old.CollectionTimeInMillis),
-			"old",
+			float64(cgroup.CPU.Stat.TimeThrottledNanos)/1e9,
+			cgroup.CPU.ControlGroup,
 		)
+	}
+
+	if geoip := stats.GeoipDownloadManager; geoip != nil {
+		for database, status := range geoip.Database {
+			ch <- prometheus.MustNewConstMetric(
+				c.GeoipDatabaseStatusInfo,
+				prometheus.GaugeValue,
+				float64(1),
+				database, status.Status,
+			)
+
+			if ts, ok := parseTimestamp(status.LastUpdatedAt); ok {
+				ch <- prometheus.MustNewConstMetric(
+					c.GeoipDatabaseLastUpdatedTimestamp,
+					prometheus.GaugeValue,
+					ts,
+					database,
+				)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.GeoipDatabaseFailCheckIn,
+				prometheus.GaugeValue,
+				float64(status.FailCheckInDays)*86400,
+				database,
+			)
+		}
 
 		ch <- prometheus.MustNewConstMetric(
-			c.GCCollectionCount,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Gc.Collectors.Old.CollectionCount),
-			"old",
+			c.GeoipDownloadSuccesses,
+			prometheus.CounterValue,
+			float64(geoip.DownloadStats.Successes),
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.GCCollectionTimeInMillis,
+			c.GeoipDownloadFailures,
 			prometheus.CounterValue,
-			float64(stats.Jvm.Gc.Collectors.Young.CollectionTimeInMillis),
-			"young",
+			float64(geoip.DownloadStats.Failures),
 		)
 
+		if ts, ok := parseTimestamp(geoip.DownloadStats.LastCheckedAt); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.GeoipDownloadLastCheckedTimestamp,
+				prometheus.GaugeValue,
+				ts,
+			)
+		}
+
+		if geoip.DownloadStats.Status != "" {
+			ch <- prometheus.MustNewConstMetric(
+				c.GeoipDownloadStatusInfo,
+				prometheus.GaugeValue,
+				float64(1),
+				geoip.DownloadStats.Status,
+			)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.EventsIn,
+		prometheus.CounterValue,
+		float64(stats.Events.In),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.EventsFiltered,
+		prometheus.CounterValue,
+		float64(stats.Events.Filtered),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.EventsOut,
+		prometheus.CounterValue,
+		float64(stats.Events.Out),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.EventsDuration,
+		prometheus.CounterValue,
+		float64(stats.Events.DurationInMillis)/1000,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.EventsQueuePushDuration,
+		prometheus.CounterValue,
+		float64(stats.Events.QueuePushDurationInMillis)/1000,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ReloadsSuccesses,
+		prometheus.CounterValue,
+		float64(stats.Reloads.Successes),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ReloadsFailures,
+		prometheus.CounterValue,
+		float64(stats.Reloads.Failures),
+	)
+
+	for pipelineID, pipeline := range stats.pipelinesByID() {
 		ch <- prometheus.MustNewConstMetric(
-			c.GCCollectionCount,
-			prometheus.GaugeValue,
-			float64(stats.Jvm.Gc.Collectors.Young.CollectionCount),
-			"young",
+			c.PipelineDuration,
+			prometheus.CounterValue,
+			float64(pipeline.Events.DurationInMillis/1000),
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessOpenFileDescriptors,
-			prometheus.GaugeValue,
-			float64(stats.Process.OpenFileDescriptors),
+			c.PipelineEventsIn,
+			prometheus.CounterValue,
+			float64(pipeline.Events.In),
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessPeakOpenFileDescriptors,
-			prometheus.GaugeValue,
-			float64(stats.Process.PeakOpenFileDescriptors),
+			c.PipelineEventsFiltered,
+			prometheus.CounterValue,
+			float64(pipeline.Events.Filtered),
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessMaxFileDescriptors,
-			prometheus.GaugeValue,
-			float64(stats.Process.MaxFileDescriptors),
+			c.PipelineEventsOut,
+			prometheus.CounterValue,
+			float64(pipeline.Events.Out),
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessMemTotalVirtualInBytes,
-			prometheus.GaugeValue,
-			float64(stats.Process.Mem.TotalVirtualInBytes),
+			c.PipelineQueuePushDuration,
+			prometheus.CounterValue,
+			float64(pipeline.Events.QueuePushDurationInMillis)/1000,
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessCPUTotalInMillis,
+			c.PipelineReloadsSuccesses,
 			prometheus.CounterValue,
-			float64(stats.Process.CPU.TotalInMillis/1000),
+			float64(pipeline.Reloads.Successes),
+			pipelineID,
 		)
 
 		ch <- prometheus.MustNewConstMetric(
-			c.ProcessCPUPercent,
-			prometheus.GaugeValue,
-			float64(stats.Process.CPU.Percent),
+			c.PipelineReloadsFailures,
+			prometheus.CounterValue,
+			float64(pipeline.Reloads.Failures),
+			pipelineID,
 		)
 
-		// For backwards compatibility with Logstash 5
-		pipelines := make(map[string]Pipeline)
-		if len(stats.Pipelines) == 0 {
-			pipelines["main"] = stats.Pipeline
-		} else {
-			pipelines = stats.Pipelines
+		if ts, ok := parseTimestamp(pipeline.Reloads.LastSuccessTimestamp); ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.PipelineReloadsLastSuccessTimestamp,
+				prometheus.GaugeValue,
+				ts,
+				pipelineID,
+			)
 		}
 
-		for pipelineID, pipeline := range pipelines {
+		if ts, ok := parseTimestamp(pipeline.Reloads.LastFailureTimestamp); ok {
 			ch <- prometheus.MustNewConstMetric(
-				c.PipelineDuration,
-				prometheus.CounterValue,
-				float64(pipeline.Events.DurationInMillis/1000),
+				c.PipelineReloadsLastFailureTimestamp,
+				prometheus.GaugeValue,
+				ts,
 				pipelineID,
 			)
+		}
 
+		if pipeline.Reloads.LastError != nil {
 			ch <- prometheus.MustNewConstMetric(
-				c.PipelineEventsIn,
-				prometheus.CounterValue,
-				float64(pipeline.Events.In),
-				pipelineID,
+				c.PipelineReloadsLastErrorInfo,
+				prometheus.GaugeValue,
+				float64(1),
+				pipelineID, sanitizeErrorMessage(pipeline.Reloads.LastError.Message),
 			)
+		}
 
+		if queue := pipeline.Queue; queue.Type != "" {
+			// Logstash >=7 reports events_count and queue_size_in_bytes, Logstash 6 only events and capacity.queue_size_in_bytes
+			queueEvents := int64(queue.Events)
+			if queue.EventsCount != nil {
+				queueEvents = *queue.EventsCount
+			}
 			ch <- prometheus.MustNewConstMetric(
-				c.PipelineEventsFiltered,
-				prometheus.CounterValue,
-				float64(pipeline.Events.Filtered),
-				pipelineID,
+				c.PipelineQueueEvents,
+				prometheus.GaugeValue,
+				float64(queueEvents),
+				pipelineID, queue.Type,
 			)
 
+			if queue.Type == "persisted" {
+				queueSize := queue.Capacity.QueueSizeInBytes
+				if queue.QueueSizeInBytes != nil {
+					queueSize = *queue.QueueSizeInBytes
+				}
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineQueueSizeInBytes,
+					prometheus.GaugeValue,
+					float64(queueSize),
+					pipelineID, queue.Type,
+				)
+
+				maxQueueSize := queue.Capacity.MaxQueueSizeInBytes
+				if maxQueueSize == 0 {
+					maxQueueSize = queue.MaxQueueSizeInBytes
+				}
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineQueueMaxSizeInBytes,
+					prometheus.GaugeValue,
+					float64(maxQueueSize),
+					pipelineID, queue.Type,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineQueuePageCapacity,
+					prometheus.GaugeValue,
+					float64(queue.Capacity.PageCapacityInBytes),
+					pipelineID, queue.Type,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineQueueMaxUnreadEvents,
+					prometheus.GaugeValue,
+					float64(queue.Capacity.MaxUnreadEvents),
+					pipelineID, queue.Type,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineQueueFreeSpaceInBytes,
+					prometheus.GaugeValue,
+					float64(queue.Data.FreeSpaceInBytes),
+					pipelineID, queue.Type,
+				)
+			}
+		}
+
+		if dlq := pipeline.DeadLetterQueue; dlq != nil {
 			ch <- prometheus.MustNewConstMetric(
-				c.PipelineEventsOut,
-				prometheus.CounterValue,
-				float64(pipeline.Events.Out),
+				c.PipelineDeadLetterQueueSizeInBytes,
+				prometheus.GaugeValue,
+				float64(dlq.QueueSizeInBytes),
 				pipelineID,
 			)
+
+			if dlq.MaxQueueSizeInBytes != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineDeadLetterQueueMaxSizeInBytes,
+					prometheus.GaugeValue,
+					float64(*dlq.MaxQueueSizeInBytes),
+					pipelineID,
+				)
+			}
+
+			if dlq.DroppedEvents != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineDeadLetterQueueDroppedEvents,
+					prometheus.CounterValue,
+					float64(*dlq.DroppedEvents),
+					pipelineID,
+				)
+			}
+
+			if dlq.ExpiredEvents != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineDeadLetterQueueExpiredEvents,
+					prometheus.CounterValue,
+					float64(*dlq.ExpiredEvents),
+					pipelineID,
+				)
+			}
+
+			if dlq.StoragePolicy != "" || dlq.LastError != "" {
+				ch <- prometheus.MustNewConstMetric(
+					c.PipelineDeadLetterQueueInfo,
+					prometheus.GaugeValue,
+					float64(1),
+					pipelineID, dlq.StoragePolicy, sanitizeErrorMessage(dlq.LastError),
+				)
+			}
+		}
+
+	}
+	return nil
+}
+
+// parseTimestamp converts a logstash ISO8601 timestamp into unix seconds, null or malformed values are skipped
+func parseTimestamp(value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-0700"} {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return float64(ts.UnixNano()) / 1e9, true
 		}
 	}
+	log.Debugf("unable to parse timestamp <%s>", value)
+	return 0, false
+}
+
+// sanitizeErrorMessage keeps the first line of an error message, collapses whitespace and bounds its length
+func sanitizeErrorMessage(message string) string {
+	if idx := strings.IndexAny(message, "\r\n"); idx >= 0 {
+		message = message[:idx]
+	}
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxErrorMessageLength {
+		message = string(runes[:maxErrorMessageLength]) + "..."
+	}
+	return message
 }
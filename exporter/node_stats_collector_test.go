@@ -3,3 +3,205 @@
 // Author  : xushiyin
 // contact : yuqingxushiyin@gmail.com
 package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestNodeStatsCollectorReloads(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewNodeStatsCollector(e)
+
+	expected := `
+# HELP logstash_node_stats_pipeline_reloads_failures_total pipeline_reloads_failures_total
+# TYPE logstash_node_stats_pipeline_reloads_failures_total counter
+logstash_node_stats_pipeline_reloads_failures_total{hostname="test",logstash_usage="logstash",pipeline="main"} 0
+logstash_node_stats_pipeline_reloads_failures_total{hostname="test",logstash_usage="logstash",pipeline="syslog"} 1
+# HELP logstash_node_stats_pipeline_reloads_last_error_info pipeline_reloads_last_error_info
+# TYPE logstash_node_stats_pipeline_reloads_last_error_info gauge
+logstash_node_stats_pipeline_reloads_last_error_info{hostname="test",logstash_usage="logstash",message="Expected one of [ \\t\\r\\n], \"#\", \"{\" at line 12, column 5 (byte 310)",pipeline="syslog"} 1
+# HELP logstash_node_stats_pipeline_reloads_last_failure_timestamp_seconds pipeline_reloads_last_failure_timestamp_seconds
+# TYPE logstash_node_stats_pipeline_reloads_last_failure_timestamp_seconds gauge
+logstash_node_stats_pipeline_reloads_last_failure_timestamp_seconds{hostname="test",logstash_usage="logstash",pipeline="syslog"} 1.6269264e+09
+# HELP logstash_node_stats_reloads_failures_total reloads_failures_total
+# TYPE logstash_node_stats_reloads_failures_total counter
+logstash_node_stats_reloads_failures_total{hostname="test",logstash_usage="logstash"} 1
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_node_stats_pipeline_reloads_failures_total",
+		"logstash_node_stats_pipeline_reloads_last_error_info",
+		"logstash_node_stats_pipeline_reloads_last_failure_timestamp_seconds",
+		"logstash_node_stats_reloads_failures_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeStatsCollectorQueue(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected string
+	}{
+		{"testdata/node_stats.json", `
+# HELP logstash_node_stats_pipeline_queue_events pipeline_queue_events
+# TYPE logstash_node_stats_pipeline_queue_events gauge
+logstash_node_stats_pipeline_queue_events{hostname="test",logstash_usage="logstash",pipeline="main",queue_type="memory"} 0
+logstash_node_stats_pipeline_queue_events{hostname="test",logstash_usage="logstash",pipeline="syslog",queue_type="persisted"} 120
+# HELP logstash_node_stats_pipeline_queue_size_bytes pipeline_queue_size_bytes
+# TYPE logstash_node_stats_pipeline_queue_size_bytes gauge
+logstash_node_stats_pipeline_queue_size_bytes{hostname="test",logstash_usage="logstash",pipeline="syslog",queue_type="persisted"} 2.62144e+06
+# HELP logstash_node_stats_pipeline_queue_free_space_bytes pipeline_queue_free_space_bytes
+# TYPE logstash_node_stats_pipeline_queue_free_space_bytes gauge
+logstash_node_stats_pipeline_queue_free_space_bytes{hostname="test",logstash_usage="logstash",pipeline="syslog",queue_type="persisted"} 5.261336576e+10
+`},
+		{"testdata/node_stats_v6.json", `
+# HELP logstash_node_stats_pipeline_queue_events pipeline_queue_events
+# TYPE logstash_node_stats_pipeline_queue_events gauge
+logstash_node_stats_pipeline_queue_events{hostname="test",logstash_usage="logstash",pipeline="main",queue_type="persisted"} 7
+# HELP logstash_node_stats_pipeline_queue_size_bytes pipeline_queue_size_bytes
+# TYPE logstash_node_stats_pipeline_queue_size_bytes gauge
+logstash_node_stats_pipeline_queue_size_bytes{hostname="test",logstash_usage="logstash",pipeline="main",queue_type="persisted"} 4096
+# HELP logstash_node_stats_pipeline_queue_free_space_bytes pipeline_queue_free_space_bytes
+# TYPE logstash_node_stats_pipeline_queue_free_space_bytes gauge
+logstash_node_stats_pipeline_queue_free_space_bytes{hostname="test",logstash_usage="logstash",pipeline="main",queue_type="persisted"} 1e+06
+`},
+	}
+	for _, ts := range tests {
+		e := newTestExporter(t, map[string]string{"/_node/stats": ts.fixture})
+		c, _ := NewNodeStatsCollector(e)
+
+		err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(ts.expected),
+			"logstash_node_stats_pipeline_queue_events",
+			"logstash_node_stats_pipeline_queue_size_bytes",
+			"logstash_node_stats_pipeline_queue_free_space_bytes",
+		)
+		if err != nil {
+			t.Errorf("%s: %s", ts.fixture, err)
+		}
+	}
+}
+
+func TestNodeStatsCollectorDeadLetterQueue(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewNodeStatsCollector(e)
+
+	expected := `
+# HELP logstash_node_stats_pipeline_dead_letter_queue_dropped_events_total pipeline_dead_letter_queue_dropped_events_total
+# TYPE logstash_node_stats_pipeline_dead_letter_queue_dropped_events_total counter
+logstash_node_stats_pipeline_dead_letter_queue_dropped_events_total{hostname="test",logstash_usage="logstash",pipeline="main"} 5
+# HELP logstash_node_stats_pipeline_dead_letter_queue_info pipeline_dead_letter_queue_info
+# TYPE logstash_node_stats_pipeline_dead_letter_queue_info gauge
+logstash_node_stats_pipeline_dead_letter_queue_info{hostname="test",last_error="no errors",logstash_usage="logstash",pipeline="main",storage_policy="drop_newer"} 1
+# HELP logstash_node_stats_pipeline_dead_letter_queue_size_bytes pipeline_dead_letter_queue_size_bytes
+# TYPE logstash_node_stats_pipeline_dead_letter_queue_size_bytes gauge
+logstash_node_stats_pipeline_dead_letter_queue_size_bytes{hostname="test",logstash_usage="logstash",pipeline="main"} 1024
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_node_stats_pipeline_dead_letter_queue_dropped_events_total",
+		"logstash_node_stats_pipeline_dead_letter_queue_info",
+		"logstash_node_stats_pipeline_dead_letter_queue_size_bytes",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeStatsCollectorCgroup(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewNodeStatsCollector(e)
+
+	expected := `
+# HELP logstash_node_stats_os_cgroup_cpu_cfs_quota_seconds os_cgroup_cpu_cfs_quota_seconds
+# TYPE logstash_node_stats_os_cgroup_cpu_cfs_quota_seconds gauge
+logstash_node_stats_os_cgroup_cpu_cfs_quota_seconds{control_group="/",hostname="test",logstash_usage="logstash"} 0.15
+# HELP logstash_node_stats_os_cgroup_cpu_throttled_seconds_total os_cgroup_cpu_throttled_seconds_total
+# TYPE logstash_node_stats_os_cgroup_cpu_throttled_seconds_total counter
+logstash_node_stats_os_cgroup_cpu_throttled_seconds_total{control_group="/",hostname="test",logstash_usage="logstash"} 12.5
+# HELP logstash_node_stats_process_cpu_load_average process_cpu_load_average
+# TYPE logstash_node_stats_process_cpu_load_average gauge
+logstash_node_stats_process_cpu_load_average{hostname="test",interval="15m",logstash_usage="logstash"} 0.75
+logstash_node_stats_process_cpu_load_average{hostname="test",interval="1m",logstash_usage="logstash"} 1.5
+logstash_node_stats_process_cpu_load_average{hostname="test",interval="5m",logstash_usage="logstash"} 1.25
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_node_stats_os_cgroup_cpu_cfs_quota_seconds",
+		"logstash_node_stats_os_cgroup_cpu_throttled_seconds_total",
+		"logstash_node_stats_process_cpu_load_average",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeStatsCollectorEvents(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewNodeStatsCollector(e)
+
+	expected := `
+# HELP logstash_node_stats_events_in_total events_in_total
+# TYPE logstash_node_stats_events_in_total counter
+logstash_node_stats_events_in_total{hostname="test",logstash_usage="logstash"} 2300
+# HELP logstash_node_stats_events_queue_push_duration_seconds_total events_queue_push_duration_seconds_total
+# TYPE logstash_node_stats_events_queue_push_duration_seconds_total counter
+logstash_node_stats_events_queue_push_duration_seconds_total{hostname="test",logstash_usage="logstash"} 2.5
+# HELP logstash_node_stats_pipeline_queue_push_duration_seconds_total pipeline_queue_push_duration_seconds_total
+# TYPE logstash_node_stats_pipeline_queue_push_duration_seconds_total counter
+logstash_node_stats_pipeline_queue_push_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main"} 2
+logstash_node_stats_pipeline_queue_push_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="syslog"} 0
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_node_stats_events_in_total",
+		"logstash_node_stats_events_queue_push_duration_seconds_total",
+		"logstash_node_stats_pipeline_queue_push_duration_seconds_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeStatsCollectorGeoip(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewNodeStatsCollector(e)
+
+	expected := `
+# HELP logstash_node_stats_geoip_database_fail_check_in_seconds geoip_database_fail_check_in_seconds
+# TYPE logstash_node_stats_geoip_database_fail_check_in_seconds gauge
+logstash_node_stats_geoip_database_fail_check_in_seconds{database="ASN",hostname="test",logstash_usage="logstash"} 0
+logstash_node_stats_geoip_database_fail_check_in_seconds{database="City",hostname="test",logstash_usage="logstash"} 2.2464e+06
+# HELP logstash_node_stats_geoip_database_last_updated_timestamp_seconds geoip_database_last_updated_timestamp_seconds
+# TYPE logstash_node_stats_geoip_database_last_updated_timestamp_seconds gauge
+logstash_node_stats_geoip_database_last_updated_timestamp_seconds{database="ASN",hostname="test",logstash_usage="logstash"} 1.7923032e+09
+logstash_node_stats_geoip_database_last_updated_timestamp_seconds{database="City",hostname="test",logstash_usage="logstash"} 1.7900568e+09
+# HELP logstash_node_stats_geoip_database_status_info geoip_database_status_info
+# TYPE logstash_node_stats_geoip_database_status_info gauge
+logstash_node_stats_geoip_database_status_info{database="ASN",hostname="test",logstash_usage="logstash",status="up_to_date"} 1
+logstash_node_stats_geoip_database_status_info{database="City",hostname="test",logstash_usage="logstash",status="to_be_expired"} 1
+# HELP logstash_node_stats_geoip_download_failures_total geoip_download_failures_total
+# TYPE logstash_node_stats_geoip_download_failures_total counter
+logstash_node_stats_geoip_download_failures_total{hostname="test",logstash_usage="logstash"} 26
+# HELP logstash_node_stats_geoip_download_last_checked_timestamp_seconds geoip_download_last_checked_timestamp_seconds
+# TYPE logstash_node_stats_geoip_download_last_checked_timestamp_seconds gauge
+logstash_node_stats_geoip_download_last_checked_timestamp_seconds{hostname="test",logstash_usage="logstash"} 1.7923086e+09
+# HELP logstash_node_stats_geoip_download_status_info geoip_download_status_info
+# TYPE logstash_node_stats_geoip_download_status_info gauge
+logstash_node_stats_geoip_download_status_info{hostname="test",logstash_usage="logstash",status="failed"} 1
+# HELP logstash_node_stats_geoip_download_successes_total geoip_download_successes_total
+# TYPE logstash_node_stats_geoip_download_successes_total counter
+logstash_node_stats_geoip_download_successes_total{hostname="test",logstash_usage="logstash"} 15
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_node_stats_geoip_database_fail_check_in_seconds",
+		"logstash_node_stats_geoip_database_last_updated_timestamp_seconds",
+		"logstash_node_stats_geoip_database_status_info",
+		"logstash_node_stats_geoip_download_failures_total",
+		"logstash_node_stats_geoip_download_last_checked_timestamp_seconds",
+		"logstash_node_stats_geoip_download_status_info",
+		"logstash_node_stats_geoip_download_successes_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
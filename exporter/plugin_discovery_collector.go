@@ -0,0 +1,174 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"regexp"
+	"strings"
+)
+
+var (
+	// DefaultPluginDiscoveryDeny skips the plugin fields already exported by the flow and plugin_stats collectors
+	DefaultPluginDiscoveryDeny = []string{
+		`^flow\.`,
+		`^events\.`,
+		`^(current|peak)_connections$`,
+		`^(matches|failures)$`,
+		`^patterns_per_field\.`,
+		`^bulk_requests\.`,
+		`^documents\.`,
+	}
+
+	invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// discovered fields matching these suffixes only grow, they are exported as counters
+	counterSuffixes = []string{"_in_millis", "successes", "failures", "matches", "_total"}
+)
+
+// PluginDiscoveryCollector walks every plugin of /_node/stats/pipelines and exports each numeric field it finds,
+// so the metrics of community plugins are available without dedicated structs
+type PluginDiscoveryCollector struct {
+	export  *LogstashExporter
+	ReqPath string
+
+	subsystem string
+	allow     []*regexp.Regexp
+	deny      []*regexp.Regexp
+}
+
+func init() {
+	registerCollector("plugin_discovery", false, func(e *LogstashExporter) (Collector, error) {
+		return NewPluginDiscoveryCollector(e)
+	})
+}
+
+func NewPluginDiscoveryCollector(e *LogstashExporter) (*PluginDiscoveryCollector, error) {
+	c := &PluginDiscoveryCollector{
+		export:    e,
+		ReqPath:   "/_node/stats/pipelines",
+		subsystem: "plugin_discovered",
+	}
+	var err error
+	if c.allow, err = compilePatterns(e.options.PluginDiscoveryAllow); err != nil {
+		return nil, errors.Wrap(err, "plugin discovery allow list")
+	}
+	if c.deny, err = compilePatterns(e.options.PluginDiscoveryDeny); err != nil {
+		return nil, errors.Wrap(err, "plugin discovery deny list")
+	}
+	return c, nil
+}
+
+func (c *PluginDiscoveryCollector) Name() string {
+	return "plugin_discovery"
+}
+
+// Describe sends nothing, the metrics are discovered at scrape time
+func (c *PluginDiscoveryCollector) Describe(_ chan<- *prometheus.Desc) {
+}
+
+func (c *PluginDiscoveryCollector) Collect(ch chan<- prometheus.Metric) error {
+	body, err := GetLogstashRaw(c.export.reqClient, c.ReqPath, c.export.options.ScrapeTimeoutMillisecond)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashRaw <%s>", c.ReqPath))
+	}
+
+	names := make(map[string]string)
+	gjson.GetBytes(body, "pipelines").ForEach(func(pipelineID, pipeline gjson.Result) bool {
+		pipeline.Get("plugins").ForEach(func(pluginType, plugins gjson.Result) bool {
+			// inputs, filters, outputs and codecs
+			pluginTypeName := strings.TrimSuffix(pluginType.String(), "s")
+			for _, plugin := range plugins.Array() {
+				labelValues := []string{pipelineID.String(), pluginTypeName, plugin.Get("name").String(), plugin.Get("id").String()}
+				c.walk(ch, names, plugin, "", labelValues)
+			}
+			return true
+		})
+		return true
+	})
+	return nil
+}
+
+// walk emits every numeric leaf below value, path is the dotted key path relative to the plugin object
+func (c *PluginDiscoveryCollector) walk(ch chan<- prometheus.Metric, names map[string]string, value gjson.Result, path string, labelValues []string) {
+	value.ForEach(func(key, child gjson.Result) bool {
+		childPath := key.String()
+		if path != "" {
+			childPath = path + "." + childPath
+		}
+		switch {
+		case child.IsObject():
+			c.walk(ch, names, child, childPath, labelValues)
+		case child.Type == gjson.Number:
+			if !c.selected(childPath) {
+				return true
+			}
+			name, valueType := discoveredMetric(childPath)
+			fqName := prometheus.BuildFQName(c.export.namespace, c.subsystem, name)
+			// different key paths may flatten to the same name, the first one claims it
+			if claimed, ok := names[fqName]; ok && claimed != childPath {
+				log.Debugf("skip discovered field <%s>, <%s> already exported as %s", childPath, claimed, fqName)
+				return true
+			}
+			names[fqName] = childPath
+			desc := prometheus.NewDesc(
+				fqName,
+				c.subsystem+"_"+name,
+				[]string{"pipeline", "plugin_type", "plugin", "plugin_id"},
+				map[string]string{"hostname": c.export.options.Hostname, "logstash_usage": c.export.options.LogstashUsage},
+			)
+			ch <- prometheus.MustNewConstMetric(desc, valueType, child.Float(), labelValues...)
+		}
+		return true
+	})
+}
+
+// selected applies the allow and deny lists to a dotted key path
+func (c *PluginDiscoveryCollector) selected(path string) bool {
+	for _, re := range c.deny {
+		if re.MatchString(path) {
+			return false
+		}
+	}
+	if len(c.allow) == 0 {
+		return true
+	}
+	for _, re := range c.allow {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// discoveredMetric flattens a dotted key path into a metric name and guesses its value type
+func discoveredMetric(path string) (string, prometheus.ValueType) {
+	name := strings.ToLower(invalidMetricChars.ReplaceAllString(path, "_"))
+	isCounter := strings.HasPrefix(path, "events.") || strings.Contains(path, "responses.")
+	for _, suffix := range counterSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			isCounter = true
+		}
+	}
+	if !isCounter {
+		return name, prometheus.GaugeValue
+	}
+	if !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return name, prometheus.CounterValue
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPluginDiscoveryCollector(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats/pipelines": "testdata/node_stats_pipelines.json"})
+	e.options.PluginDiscoveryAllow = []string{`^current_connections$`, `^bulk_requests\.`}
+	e.options.PluginDiscoveryDeny = []string{`^bulk_requests\.with_errors$`}
+	c, err := NewPluginDiscoveryCollector(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP logstash_plugin_discovered_bulk_requests_failures_total plugin_discovered_bulk_requests_failures_total
+# TYPE logstash_plugin_discovered_bulk_requests_failures_total counter
+logstash_plugin_discovered_bulk_requests_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 1
+# HELP logstash_plugin_discovered_bulk_requests_responses_200_total plugin_discovered_bulk_requests_responses_200_total
+# TYPE logstash_plugin_discovered_bulk_requests_responses_200_total counter
+logstash_plugin_discovered_bulk_requests_responses_200_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 42
+# HELP logstash_plugin_discovered_bulk_requests_responses_413_total plugin_discovered_bulk_requests_responses_413_total
+# TYPE logstash_plugin_discovered_bulk_requests_responses_413_total counter
+logstash_plugin_discovered_bulk_requests_responses_413_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 1
+# HELP logstash_plugin_discovered_bulk_requests_successes_total plugin_discovered_bulk_requests_successes_total
+# TYPE logstash_plugin_discovered_bulk_requests_successes_total counter
+logstash_plugin_discovered_bulk_requests_successes_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 40
+# HELP logstash_plugin_discovered_current_connections plugin_discovered_current_connections
+# TYPE logstash_plugin_discovered_current_connections gauge
+logstash_plugin_discovered_current_connections{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in",plugin_type="input"} 12
+`
+	err = testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewPluginDiscoveryCollectorInvalidPattern(t *testing.T) {
+	e := newTestExporter(t, nil)
+	e.options.PluginDiscoveryDeny = []string{`(`}
+	if _, err := NewPluginDiscoveryCollector(e); err == nil {
+		t.Error("expected an error for an invalid deny pattern")
+	}
+}
+
+func TestPluginDiscoveryCollectorRegistry(t *testing.T) {
+	opts := newTestExporter(t, map[string]string{
+		"/":                      "testdata/root.json",
+		"/_node/stats":           "testdata/node_stats_pipelines.json",
+		"/_node/stats/pipelines": "testdata/node_stats_pipelines.json",
+	}).options
+	opts.Collectors = map[string]bool{"plugin_discovery": true}
+	opts.PluginDiscoveryDeny = DefaultPluginDiscoveryDeny
+	opts.Registry = prometheus.NewRegistry()
+	e, err := NewLogstashExporter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the discovered metrics are served next to the described ones, without the fields plugin_stats exports
+	expected := `
+# HELP logstash_plugin_discovered_bytes_written plugin_discovered_bytes_written
+# TYPE logstash_plugin_discovered_bytes_written gauge
+logstash_plugin_discovered_bytes_written{hostname="test",logstash_usage="logstash",pipeline="main",plugin="stdout",plugin_id="stdout_out",plugin_type="output"} 5120
+# HELP logstash_plugin_input_current_connections input_current_connections
+# TYPE logstash_plugin_input_current_connections gauge
+logstash_plugin_input_current_connections{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in"} 12
+`
+	err = testutil.GatherAndCompare(opts.Registry, strings.NewReader(expected),
+		"logstash_plugin_discovered_bytes_written",
+		"logstash_plugin_discovered_current_connections",
+		"logstash_plugin_discovered_events_out_total",
+		"logstash_plugin_input_current_connections",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, opts.MetricsPath, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "logstash_plugin_discovered_bytes_written{") {
+		t.Errorf("discovered metrics not served on %s, status %d", opts.MetricsPath, rec.Code)
+	}
+}
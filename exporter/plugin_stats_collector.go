@@ -0,0 +1,340 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PluginStatsCollector exports the plugin specific counters, such as beats connections, grok matches or elasticsearch bulk responses
+type PluginStatsCollector struct {
+	export *LogstashExporter
+
+	EventsIn  *prometheus.Desc
+	EventsOut *prometheus.Desc
+	Duration  *prometheus.Desc
+
+	InputCurrentConnections *prometheus.Desc
+	InputPeakConnections    *prometheus.Desc
+	InputQueuePushDuration  *prometheus.Desc
+
+	FilterMatches          *prometheus.Desc
+	FilterFailures         *prometheus.Desc
+	FilterPatternsPerField *prometheus.Desc
+
+	OutputBulkRequestsSuccesses  *prometheus.Desc
+	OutputBulkRequestsFailures   *prometheus.Desc
+	OutputBulkRequestsWithErrors *prometheus.Desc
+	OutputBulkRequestsResponses  *prometheus.Desc
+
+	OutputDocumentsSuccesses            *prometheus.Desc
+	OutputDocumentsNonRetryableFailures *prometheus.Desc
+	OutputDocumentsRetryableFailures    *prometheus.Desc
+}
+
+func init() {
+	registerCollector("plugin_stats", true, func(e *LogstashExporter) (Collector, error) {
+		return NewPluginStatsCollector(e)
+	})
+}
+
+func NewPluginStatsCollector(e *LogstashExporter) (*PluginStatsCollector, error) {
+	const subsystem = "plugin"
+	return &PluginStatsCollector{
+		export: e,
+
+		EventsIn: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_in_total"),
+			"events_in_total",
+			[]string{"pipeline", "plugin_type", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		EventsOut: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "events_out_total"),
+			"events_out_total",
+			[]string{"pipeline", "plugin_type", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		Duration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "duration_seconds_total"),
+			"duration_seconds_total",
+			[]string{"pipeline", "plugin_type", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		InputCurrentConnections: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "input_current_connections"),
+			"input_current_connections",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		InputPeakConnections: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "input_peak_connections"),
+			"input_peak_connections",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		InputQueuePushDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "input_queue_push_duration_seconds_total"),
+			"input_queue_push_duration_seconds_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		FilterMatches: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "filter_matches_total"),
+			"filter_matches_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		FilterFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "filter_failures_total"),
+			"filter_failures_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		FilterPatternsPerField: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "filter_patterns_per_field"),
+			"filter_patterns_per_field",
+			[]string{"pipeline", "plugin", "plugin_id", "field"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputBulkRequestsSuccesses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_bulk_requests_successes_total"),
+			"output_bulk_requests_successes_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputBulkRequestsFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_bulk_requests_failures_total"),
+			"output_bulk_requests_failures_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputBulkRequestsWithErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_bulk_requests_with_errors_total"),
+			"output_bulk_requests_with_errors_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputBulkRequestsResponses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_bulk_requests_responses_total"),
+			"output_bulk_requests_responses_total",
+			[]string{"pipeline", "plugin", "plugin_id", "status_code"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputDocumentsSuccesses: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_documents_successes_total"),
+			"output_documents_successes_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputDocumentsNonRetryableFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_documents_non_retryable_failures_total"),
+			"output_documents_non_retryable_failures_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+
+		OutputDocumentsRetryableFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(e.namespace, subsystem, "output_documents_retryable_failures_total"),
+			"output_documents_retryable_failures_total",
+			[]string{"pipeline", "plugin", "plugin_id"},
+			map[string]string{"hostname": e.options.Hostname, "logstash_usage": e.options.LogstashUsage},
+		),
+	}, nil
+}
+
+func (c *PluginStatsCollector) Name() string {
+	return "plugin_stats"
+}
+
+func (c *PluginStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	describeFields(c, ch)
+}
+
+func (c *PluginStatsCollector) Collect(ch chan<- prometheus.Metric) error {
+	stats, err := c.export.nodeStats()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("GetLogstashNodeStats <%s>", NodeStatsPath))
+	}
+
+	// For backwards compatibility with Logstash 5
+	for pipelineID, pipeline := range stats.pipelinesByID() {
+		// inputs only report the events they push to the queue
+		for _, plugin := range pipeline.Plugins.Inputs {
+			ch <- prometheus.MustNewConstMetric(
+				c.EventsOut,
+				prometheus.CounterValue,
+				float64(plugin.Events.Out),
+				pipelineID, "input", plugin.Name, plugin.ID,
+			)
+
+			if plugin.CurrentConnections != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.InputCurrentConnections,
+					prometheus.GaugeValue,
+					float64(*plugin.CurrentConnections),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+			}
+
+			if plugin.PeakConnections != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.InputPeakConnections,
+					prometheus.GaugeValue,
+					float64(*plugin.PeakConnections),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				c.InputQueuePushDuration,
+				prometheus.CounterValue,
+				float64(plugin.Events.QueuePushDurationInMillis)/1000,
+				pipelineID, plugin.Name, plugin.ID,
+			)
+		}
+
+		for _, plugin := range pipeline.Plugins.Filters {
+			ch <- prometheus.MustNewConstMetric(
+				c.EventsIn,
+				prometheus.CounterValue,
+				float64(plugin.Events.In),
+				pipelineID, "filter", plugin.Name, plugin.ID,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.EventsOut,
+				prometheus.CounterValue,
+				float64(plugin.Events.Out),
+				pipelineID, "filter", plugin.Name, plugin.ID,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.Duration,
+				prometheus.CounterValue,
+				float64(plugin.Events.DurationInMillis)/1000,
+				pipelineID, "filter", plugin.Name, plugin.ID,
+			)
+
+			if plugin.Matches != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.FilterMatches,
+					prometheus.CounterValue,
+					float64(*plugin.Matches),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+			}
+
+			if plugin.Failures != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.FilterFailures,
+					prometheus.CounterValue,
+					float64(*plugin.Failures),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+			}
+
+			for field, patterns := range plugin.PatternsPerField {
+				ch <- prometheus.MustNewConstMetric(
+					c.FilterPatternsPerField,
+					prometheus.GaugeValue,
+					float64(patterns),
+					pipelineID, plugin.Name, plugin.ID, field,
+				)
+			}
+		}
+
+		for _, plugin := range pipeline.Plugins.Outputs {
+			ch <- prometheus.MustNewConstMetric(
+				c.EventsIn,
+				prometheus.CounterValue,
+				float64(plugin.Events.In),
+				pipelineID, "output", plugin.Name, plugin.ID,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.EventsOut,
+				prometheus.CounterValue,
+				float64(plugin.Events.Out),
+				pipelineID, "output", plugin.Name, plugin.ID,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.Duration,
+				prometheus.CounterValue,
+				float64(plugin.Events.DurationInMillis)/1000,
+				pipelineID, "output", plugin.Name, plugin.ID,
+			)
+
+			if bulk := plugin.BulkRequests; bulk != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputBulkRequestsSuccesses,
+					prometheus.CounterValue,
+					float64(bulk.Successes),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputBulkRequestsFailures,
+					prometheus.CounterValue,
+					float64(bulk.Failures),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputBulkRequestsWithErrors,
+					prometheus.CounterValue,
+					float64(bulk.WithErrors),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+
+				for statusCode, responses := range bulk.Responses {
+					ch <- prometheus.MustNewConstMetric(
+						c.OutputBulkRequestsResponses,
+						prometheus.CounterValue,
+						float64(responses),
+						pipelineID, plugin.Name, plugin.ID, statusCode,
+					)
+				}
+			}
+
+			if documents := plugin.Documents; documents != nil {
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputDocumentsSuccesses,
+					prometheus.CounterValue,
+					float64(documents.Successes),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputDocumentsNonRetryableFailures,
+					prometheus.CounterValue,
+					float64(documents.NonRetryableFailures),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+
+				ch <- prometheus.MustNewConstMetric(
+					c.OutputDocumentsRetryableFailures,
+					prometheus.CounterValue,
+					float64(documents.RetryableFailures),
+					pipelineID, plugin.Name, plugin.ID,
+				)
+			}
+		}
+	}
+	return nil
+}
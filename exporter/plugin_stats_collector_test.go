@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestPluginStatsCollectorEvents(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats.json"})
+	c, _ := NewPluginStatsCollector(e)
+
+	expected := `
+# HELP logstash_plugin_duration_seconds_total duration_seconds_total
+# TYPE logstash_plugin_duration_seconds_total counter
+logstash_plugin_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 30.5
+logstash_plugin_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access",plugin_type="filter"} 4.2
+logstash_plugin_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="syslog",plugin="stdout",plugin_id="stdout_out",plugin_type="output"} 0.1
+# HELP logstash_plugin_events_in_total events_in_total
+# TYPE logstash_plugin_events_in_total counter
+logstash_plugin_events_in_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 1990
+logstash_plugin_events_in_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access",plugin_type="filter"} 2000
+logstash_plugin_events_in_total{hostname="test",logstash_usage="logstash",pipeline="syslog",plugin="stdout",plugin_id="stdout_out",plugin_type="output"} 300
+# HELP logstash_plugin_events_out_total events_out_total
+# TYPE logstash_plugin_events_out_total counter
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in",plugin_type="input"} 2000
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 1980
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access",plugin_type="filter"} 1995
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="syslog",plugin="stdout",plugin_id="stdout_out",plugin_type="output"} 300
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="syslog",plugin="tcp",plugin_id="tcp_in",plugin_type="input"} 300
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_plugin_duration_seconds_total",
+		"logstash_plugin_events_in_total",
+		"logstash_plugin_events_out_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPluginStatsCollectorFilters(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats_pipelines.json"})
+	c, _ := NewPluginStatsCollector(e)
+
+	expected := `
+# HELP logstash_plugin_filter_failures_total filter_failures_total
+# TYPE logstash_plugin_filter_failures_total counter
+logstash_plugin_filter_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="date",plugin_id="date_ts"} 5
+logstash_plugin_filter_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access"} 100
+# HELP logstash_plugin_filter_matches_total filter_matches_total
+# TYPE logstash_plugin_filter_matches_total counter
+logstash_plugin_filter_matches_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="date",plugin_id="date_ts"} 1990
+logstash_plugin_filter_matches_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access"} 1900
+# HELP logstash_plugin_filter_patterns_per_field filter_patterns_per_field
+# TYPE logstash_plugin_filter_patterns_per_field gauge
+logstash_plugin_filter_patterns_per_field{field="[http][request][headers]",hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access"} 1
+logstash_plugin_filter_patterns_per_field{field="message",hostname="test",logstash_usage="logstash",pipeline="main",plugin="grok",plugin_id="grok_access"} 2
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_plugin_filter_failures_total",
+		"logstash_plugin_filter_matches_total",
+		"logstash_plugin_filter_patterns_per_field",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPluginStatsCollectorElasticsearchOutput(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats_pipelines.json"})
+	c, _ := NewPluginStatsCollector(e)
+
+	expected := `
+# HELP logstash_plugin_output_bulk_requests_failures_total output_bulk_requests_failures_total
+# TYPE logstash_plugin_output_bulk_requests_failures_total counter
+logstash_plugin_output_bulk_requests_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 1
+# HELP logstash_plugin_output_bulk_requests_responses_total output_bulk_requests_responses_total
+# TYPE logstash_plugin_output_bulk_requests_responses_total counter
+logstash_plugin_output_bulk_requests_responses_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",status_code="200"} 42
+logstash_plugin_output_bulk_requests_responses_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",status_code="413"} 1
+# HELP logstash_plugin_output_bulk_requests_successes_total output_bulk_requests_successes_total
+# TYPE logstash_plugin_output_bulk_requests_successes_total counter
+logstash_plugin_output_bulk_requests_successes_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 40
+# HELP logstash_plugin_output_bulk_requests_with_errors_total output_bulk_requests_with_errors_total
+# TYPE logstash_plugin_output_bulk_requests_with_errors_total counter
+logstash_plugin_output_bulk_requests_with_errors_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 2
+# HELP logstash_plugin_output_documents_non_retryable_failures_total output_documents_non_retryable_failures_total
+# TYPE logstash_plugin_output_documents_non_retryable_failures_total counter
+logstash_plugin_output_documents_non_retryable_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 3
+# HELP logstash_plugin_output_documents_retryable_failures_total output_documents_retryable_failures_total
+# TYPE logstash_plugin_output_documents_retryable_failures_total counter
+logstash_plugin_output_documents_retryable_failures_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 2
+# HELP logstash_plugin_output_documents_successes_total output_documents_successes_total
+# TYPE logstash_plugin_output_documents_successes_total counter
+logstash_plugin_output_documents_successes_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out"} 1975
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_plugin_output_bulk_requests_failures_total",
+		"logstash_plugin_output_bulk_requests_responses_total",
+		"logstash_plugin_output_bulk_requests_successes_total",
+		"logstash_plugin_output_bulk_requests_with_errors_total",
+		"logstash_plugin_output_documents_non_retryable_failures_total",
+		"logstash_plugin_output_documents_retryable_failures_total",
+		"logstash_plugin_output_documents_successes_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPluginStatsCollectorInputs(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats_pipelines.json"})
+	c, _ := NewPluginStatsCollector(e)
+
+	expected := `
+# HELP logstash_plugin_input_current_connections input_current_connections
+# TYPE logstash_plugin_input_current_connections gauge
+logstash_plugin_input_current_connections{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in"} 12
+# HELP logstash_plugin_input_peak_connections input_peak_connections
+# TYPE logstash_plugin_input_peak_connections gauge
+logstash_plugin_input_peak_connections{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in"} 40
+# HELP logstash_plugin_input_queue_push_duration_seconds_total input_queue_push_duration_seconds_total
+# TYPE logstash_plugin_input_queue_push_duration_seconds_total counter
+logstash_plugin_input_queue_push_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in"} 1.5
+logstash_plugin_input_queue_push_duration_seconds_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="generator",plugin_id="generator_in"} 0
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_plugin_input_current_connections",
+		"logstash_plugin_input_peak_connections",
+		"logstash_plugin_input_queue_push_duration_seconds_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPluginStatsCollectorLogstash5(t *testing.T) {
+	e := newTestExporter(t, map[string]string{"/_node/stats": "testdata/node_stats_v5.json"})
+	c, _ := NewPluginStatsCollector(e)
+
+	expected := `
+# HELP logstash_plugin_events_out_total events_out_total
+# TYPE logstash_plugin_events_out_total counter
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in",plugin_type="input"} 100
+logstash_plugin_events_out_total{hostname="test",logstash_usage="logstash",pipeline="main",plugin="elasticsearch",plugin_id="es_out",plugin_type="output"} 100
+# HELP logstash_plugin_input_current_connections input_current_connections
+# TYPE logstash_plugin_input_current_connections gauge
+logstash_plugin_input_current_connections{hostname="test",logstash_usage="logstash",pipeline="main",plugin="beats",plugin_id="beats_in"} 3
+`
+	err := testutil.CollectAndCompare(collectorAdapter{c}, strings.NewReader(expected),
+		"logstash_plugin_events_out_total",
+		"logstash_plugin_input_current_connections",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
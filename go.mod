@@ -9,4 +9,5 @@ require (
 	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/pflag v1.0.5
 	github.com/tidwall/gjson v1.8.1
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"fmt"
 	"github.com/Achillesxu/logstash_exporter/exporter"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"time"
 )
 
 var (
@@ -29,6 +31,18 @@ var (
 	logstashUsage       string
 	isDebug             bool
 	scrapeTimeout       int64
+	configFile          string
+	flowWindows         []string
+
+	// enabled and disabled state of every registered collector, set by --collector.<name> and --no-collector.<name>
+	collectorFlags   = make(map[string]*bool)
+	noCollectorFlags = make(map[string]*bool)
+
+	pluginDiscoveryAllow []string
+	pluginDiscoveryDeny  []string
+
+	hotThreadsCount    int
+	hotThreadsInterval time.Duration
 )
 
 func init() {
@@ -36,6 +50,16 @@ func init() {
 	flag.StringVarP(&exporterBindAddress, "web_listen_address", "w", ":9198", "http server for /metric and more")
 	flag.StringVarP(&logstashUsage, "logstash_usage", "u", "logstash", "logstash_usage, for instance: sms, to cope with sms message")
 	flag.Int64VarP(&scrapeTimeout, "scrape_timeout", "s", 10000, "request single logstash monitor api timeout milliseconds, for instance: -s 10000, the timeout number is 10000 millisecond")
+	flag.StringVarP(&configFile, "config_file", "c", "", "optional yaml config file, for instance: mapping rules from logstash api json paths to metrics")
+	flag.StringSliceVar(&flowWindows, "flow_windows", nil, "logstash >=8.5 flow metric windows to export, for instance: --flow_windows current,lifetime, all windows are exported when empty")
+	for name, enabled := range exporter.CollectorDefaults() {
+		collectorFlags[name] = flag.Bool("collector."+name, enabled, fmt.Sprintf("enable the %s collector", name))
+		noCollectorFlags[name] = flag.Bool("no-collector."+name, false, fmt.Sprintf("disable the %s collector", name))
+	}
+	flag.StringArrayVar(&pluginDiscoveryAllow, "plugin_discovery_allow", nil, "regexp on plugin field key paths, for instance: events\\..*, only matching fields are discovered, repeatable")
+	flag.StringArrayVar(&pluginDiscoveryDeny, "plugin_discovery_deny", exporter.DefaultPluginDiscoveryDeny, "regexp on plugin field key paths, matching fields are not discovered, repeatable")
+	flag.IntVar(&hotThreadsCount, "hot_threads_count", exporter.DefaultHotThreadsCount, "number of busiest threads requested from /_node/hot_threads")
+	flag.DurationVar(&hotThreadsInterval, "hot_threads_interval", exporter.DefaultHotThreadsInterval, "minimum interval between two /_node/hot_threads requests, the last result is exported in between")
 	flag.BoolVar(&isDebug, "debug", false, "Output verbose debug information")
 }
 
@@ -72,6 +96,15 @@ func main() {
 		log.Fatalf("get hostname failed: %#v", err)
 	}
 
+	cfg := &exporter.Config{}
+	if configFile != "" {
+		cfg, err = exporter.LoadConfig(configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("loaded config file %s with %d mapping rules", configFile, len(cfg.Mappings))
+	}
+
 	registry := prometheus.NewRegistry()
 
 	exp, err := exporter.NewLogstashExporter(exporter.Options{
@@ -81,6 +114,13 @@ func main() {
 		EndPoint:                 logstashEndpoint,
 		MetricsPath:              MetricsPath,
 		ScrapeTimeoutMillisecond: scrapeTimeout,
+		Collectors:               selectCollectors(cfg),
+		FlowWindows:              flowWindows,
+		PluginDiscoveryAllow:     pluginDiscoveryAllow,
+		PluginDiscoveryDeny:      pluginDiscoveryDeny,
+		MappingRules:             cfg.Mappings,
+		HotThreadsCount:          hotThreadsCount,
+		HotThreadsInterval:       hotThreadsInterval,
 		Registry:                 registry,
 		BuildInfo: exporter.BuildInfo{
 			Version:   BuildVersion,
@@ -95,3 +135,26 @@ func main() {
 	log.Infof("logstash_endpoint addr: %s", logstashEndpoint)
 	log.Fatal(http.ListenAndServe(exporterBindAddress, exp))
 }
+
+// selectCollectors merges the collectors of the config file with the collector flags, flags take precedence
+func selectCollectors(cfg *exporter.Config) map[string]bool {
+	collectors := make(map[string]bool)
+	for name, enabled := range cfg.Collectors {
+		collectors[name] = enabled
+	}
+	for name, enabled := range collectorFlags {
+		if flag.CommandLine.Changed("collector." + name) {
+			collectors[name] = *enabled
+		}
+	}
+	for name, disabled := range noCollectorFlags {
+		if !flag.CommandLine.Changed("no-collector." + name) {
+			continue
+		}
+		if flag.CommandLine.Changed("collector."+name) && *collectorFlags[name] == *disabled {
+			log.Fatalf("conflicting --collector.%s and --no-collector.%s flags", name, name)
+		}
+		collectors[name] = !*disabled
+	}
+	return collectors
+}